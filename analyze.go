@@ -0,0 +1,95 @@
+package tablemap
+
+import (
+	"reflect"
+	"slices"
+)
+
+// maxReportSamples is the maximum number of failing values kept per column in a Report
+const maxReportSamples = 5
+
+// Report summarizes how table data would convert to a struct type.
+type Report struct {
+	// Rows is the number of data rows analyzed.
+	Rows int
+	// InconsistentRows is the number of rows whose length differs from the header.
+	// Unless AllowRaggedRows is set, unmarshaling fails for these rows.
+	// Their cells are analyzed nonetheless.
+	InconsistentRows int
+	// Columns describes each header column that maps to a field, in header order.
+	Columns []ColumnReport
+	// Unmapped lists the header columns that do not map to any field, in header order.
+	Unmapped []string
+}
+
+// ColumnReport describes the conversion of the cells of a single column.
+type ColumnReport struct {
+	Name      string
+	Succeeded int // Number of cells converted successfully
+	Failed    int // Number of cells that failed to convert
+	// Samples holds up to 5 of the failing cells, in the order they were found.
+	Samples []string
+}
+
+// Analyze reports how many cells of each column would convert to the fields of v
+// without error, as a dry run of UnmarshalWithOptions.
+// v is as for UnmarshalWithOptions, and is only used for its type; it is not modified.
+// Each cell is converted independently, so a single row may fail in several columns.
+func Analyze(header []string, data [][]string, v any, opts *Options) (*Report, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if header == nil && opts.Header != nil {
+		header = opts.Header
+	}
+
+	structType, err := targetStructType(v)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newRow(structType, header, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Rows:     len(data),
+		Unmapped: slices.Clone(r.unmapped),
+	}
+
+	// Index of the ColumnReport of each header column, or -1 if unmapped
+	reportIndex := make([]int, len(r.header))
+	for i, h := range r.header {
+		reportIndex[i] = -1
+		if _, ok := r.fields[r.columns[i]]; ok {
+			reportIndex[i] = len(report.Columns)
+			report.Columns = append(report.Columns, ColumnReport{Name: h})
+		}
+	}
+
+	for _, rowData := range data {
+		if len(rowData) != len(r.header) {
+			report.InconsistentRows++
+			rowData = rowData[:min(len(rowData), len(r.header))]
+		}
+
+		// Convert into a throwaway struct, which is discarded with the row
+		structVal := reflect.New(structType).Elem()
+		for i, col := range rowData {
+			if reportIndex[i] < 0 {
+				continue
+			}
+			c := &report.Columns[reportIndex[i]]
+			if err := r.setCell(structVal, r.fields[r.columns[i]], col); err != nil {
+				c.Failed++
+				if len(c.Samples) < maxReportSamples {
+					c.Samples = append(c.Samples, col)
+				}
+				continue
+			}
+			c.Succeeded++
+		}
+	}
+
+	return report, nil
+}
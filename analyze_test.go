@@ -0,0 +1,86 @@
+package tablemap_test
+
+import (
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	type Record struct {
+		Name   string  `table:"name"`
+		Age    int     `table:"age"`
+		Active bool    `table:"active"`
+		Score  *int    `table:"score"`
+		Rate   float64 `table:"rate"`
+	}
+
+	header := []string{"name", "age", "extra", "active", "score"}
+	data := [][]string{
+		{"Alice", "23", "x", "true", "\\N"},
+		{"Bob", "old", "y", "maybe", "10"},
+		{"Carol", "1.5", "z", "false", "ten"},
+		{"Dave", "40"},
+		{"Eve", "7", "x", "yes", "1", "extra cell"},
+	}
+
+	var target []Record
+	report, err := tablemap.Analyze(header, data, &target, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &tablemap.Report{
+		Rows:             5,
+		InconsistentRows: 2,
+		Columns: []tablemap.ColumnReport{
+			{Name: "name", Succeeded: 5},
+			{Name: "age", Succeeded: 3, Failed: 2, Samples: []string{"old", "1.5"}},
+			{Name: "active", Succeeded: 2, Failed: 2, Samples: []string{"maybe", "yes"}},
+			{Name: "score", Succeeded: 3, Failed: 1, Samples: []string{"ten"}},
+		},
+		Unmapped: []string{"extra"},
+	}, report)
+	assert.Nil(t, target)
+}
+
+func TestAnalyze_options(t *testing.T) {
+	type Record struct {
+		Price int `table:"price"`
+	}
+
+	data := make([][]string, 7)
+	for i := range data {
+		data[i] = []string{"$" + string(rune('0'+i))}
+	}
+
+	t.Run("samples are bounded", func(t *testing.T) {
+		report, err := tablemap.Analyze([]string{"price"}, data, &[]Record{}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, report.Columns[0].Failed)
+		assert.Equal(t, []string{"$0", "$1", "$2", "$3", "$4"}, report.Columns[0].Samples)
+	})
+
+	t.Run("column transforms", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithColumnTransforms(map[string]func(string) string{
+			"price": func(s string) string { return s[1:] },
+		})
+		report, err := tablemap.Analyze([]string{"price"}, data, &[]*Record{}, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []tablemap.ColumnReport{{Name: "price", Succeeded: 7}}, report.Columns)
+	})
+
+	t.Run("header in the data takes precedence over Options.Header", func(t *testing.T) {
+		type Pair struct {
+			ID   int    `table:"id"`
+			Name string `table:"name"`
+		}
+		opts := tablemap.DefaultOptions().WithHeader([]string{"name", "id"})
+		report, err := tablemap.Analyze([]string{"id", "name"}, [][]string{{"1", "bob"}}, &[]Pair{}, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []tablemap.ColumnReport{{Name: "id", Succeeded: 1}, {Name: "name", Succeeded: 1}}, report.Columns)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		_, err := tablemap.Analyze([]string{"price"}, data, Record{}, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,68 @@
+package tablemap
+
+import (
+	"io"
+	"slices"
+)
+
+// Table accumulates rows of struct T one at a time, for building a table
+// in a loop without collecting a slice of T first.
+// The field mapping of T is computed once, when the Table is created.
+type Table[T any] struct {
+	handler *RowHandler[T]
+	rows    [][]string
+}
+
+// NewTable creates an empty Table for struct T with custom options.
+// The columns are those produced by MarshalWithOptions for T.
+func NewTable[T any](opts *Options) (*Table[T], error) {
+	handler, err := NewRowHandler[T](nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Table[T]{handler: handler}, nil
+}
+
+// AddRow marshals v and appends it as a row of the table.
+// If Options.OmitZeroRows is set and the fields of all columns of v are zero, v is skipped.
+func (t *Table[T]) AddRow(v T) error {
+	if t.handler.OmitRow(&v) {
+		return nil
+	}
+	row, err := t.handler.MarshalRow(&v)
+	if err != nil {
+		return err
+	}
+	t.rows = append(t.rows, row)
+	return nil
+}
+
+// Len returns the number of rows in the table.
+func (t *Table[T]) Len() int {
+	return len(t.rows)
+}
+
+// Reset removes all rows from the table, keeping its columns.
+func (t *Table[T]) Reset() {
+	t.rows = nil
+}
+
+// Marshal returns the header and rows of the table, as MarshalWithOptions would for the added values.
+// The rows are copied, so the table can keep growing independently.
+func (t *Table[T]) Marshal() ([]string, [][]string) {
+	data := make([][]string, len(t.rows))
+	for i, row := range t.rows {
+		data[i] = slices.Clone(row)
+	}
+	return t.handler.Header(), data
+}
+
+// Write writes the header and rows of the table in the given format to w.
+// The header is written even if the table has no rows.
+func (t *Table[T]) Write(w io.Writer, format Format) error {
+	delim, err := format.delimiter()
+	if err != nil {
+		return err
+	}
+	return writeTable(w, delim, t.handler.Header(), t.rows)
+}
@@ -0,0 +1,95 @@
+package tablemap_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  *int   `table:"age"`
+	}
+
+	table, err := tablemap.NewTable[Record](nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, table.Len())
+
+	header, data := table.Marshal()
+	assert.Equal(t, []string{"name", "age"}, header)
+	assert.Empty(t, data)
+
+	for _, r := range []Record{{Name: "Alice", Age: P(23)}, {Name: "Bob, Jr."}} {
+		assert.NoError(t, table.AddRow(r))
+	}
+	assert.Equal(t, 2, table.Len())
+
+	header, data = table.Marshal()
+	assert.Equal(t, []string{"name", "age"}, header)
+	assert.Equal(t, [][]string{{"Alice", "23"}, {"Bob, Jr.", "\\N"}}, data)
+
+	// The result is the same as marshaling the values at once
+	expectedHeader, expectedData, err := tablemap.Marshal([]Record{{Name: "Alice", Age: P(23)}, {Name: "Bob, Jr."}})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedHeader, header)
+	assert.Equal(t, expectedData, data)
+
+	t.Run("write", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, table.Write(&buf, tablemap.FormatCSV))
+		assert.Equal(t, "name,age\nAlice,23\n\"Bob, Jr.\",\\N\n", buf.String())
+
+		buf.Reset()
+		assert.NoError(t, table.Write(&buf, tablemap.FormatTSV))
+		assert.Equal(t, "name\tage\nAlice\t23\nBob, Jr.\t\\N\n", buf.String())
+
+		assert.Error(t, table.Write(&buf, tablemap.Format(99)))
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		table.Reset()
+		assert.Equal(t, 0, table.Len())
+
+		var buf bytes.Buffer
+		assert.NoError(t, table.Write(&buf, tablemap.FormatCSV))
+		assert.Equal(t, "name,age\n", buf.String())
+
+		// Rows returned earlier are not affected
+		assert.Len(t, data, 2)
+	})
+}
+
+func TestNewTable_options(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	table, err := tablemap.NewTable[Record](tablemap.DefaultOptions().WithHeader([]string{"age"}))
+	assert.NoError(t, err)
+	assert.NoError(t, table.AddRow(Record{Name: "Alice", Age: 23}))
+	header, data := table.Marshal()
+	assert.Equal(t, []string{"age"}, header)
+	assert.Equal(t, [][]string{{"23"}}, data)
+
+	_, err = tablemap.NewTable[int](nil)
+	assert.Error(t, err)
+
+	t.Run("omit zero rows", func(t *testing.T) {
+		table, err := tablemap.NewTable[Record](tablemap.DefaultOptions().WithOmitZeroRows(true))
+		assert.NoError(t, err)
+		assert.NoError(t, table.AddRow(Record{}))
+		assert.NoError(t, table.AddRow(Record{Name: "Alice"}))
+		assert.Equal(t, 1, table.Len())
+
+		records := []Record{{}, {Name: "Alice"}}
+		expectedHeader, expectedData, err := tablemap.MarshalWithOptions(records, tablemap.DefaultOptions().WithOmitZeroRows(true))
+		assert.NoError(t, err)
+		header, data := table.Marshal()
+		assert.Equal(t, expectedHeader, header)
+		assert.Equal(t, expectedData, data)
+	})
+}
@@ -0,0 +1,19 @@
+package tablemap
+
+// Convert re-maps a slice of structs of type From into a slice of structs of type To,
+// matching fields by tag.
+// Columns of From that are not in To are dropped, and fields of To that are not in From are left at their zero values.
+// With CollectErrors set, the successfully converted rows are returned along with the errors.
+func Convert[From, To any](src []From, opts *Options) ([]To, error) {
+	header, data, err := MarshalWithOptions(src, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]To, 0, len(data))
+	err = UnmarshalWithOptions(header, data, &result, opts)
+	if err != nil && (opts == nil || !opts.CollectErrors) {
+		return nil, err
+	}
+	return result, err
+}
@@ -0,0 +1,58 @@
+package tablemap_test
+
+import (
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	type Source struct {
+		ID    int     `table:"id"`
+		Name  string  `table:"name"`
+		Email *string `table:"email"`
+		Memo  string  `table:"memo"`
+	}
+
+	type Target struct {
+		Name  string  `table:"name"`
+		ID    int64   `table:"id"`
+		Email *string `table:"email"`
+		Score float64 `table:"score"`
+	}
+
+	src := []Source{
+		{ID: 1, Name: "Alice", Email: P("alice@example.com"), Memo: "dropped"},
+		{ID: 2, Name: "Bob"},
+	}
+
+	t.Run("overlapping tags", func(t *testing.T) {
+		result, err := tablemap.Convert[Source, Target](src, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []Target{
+			{Name: "Alice", ID: 1, Email: P("alice@example.com")},
+			{Name: "Bob", ID: 2},
+		}, result)
+	})
+
+	t.Run("empty source", func(t *testing.T) {
+		result, err := tablemap.Convert[Source, Target](nil, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("incompatible field", func(t *testing.T) {
+		type Strict struct {
+			Email string `table:"email"`
+		}
+
+		// A nil email cannot be set to a non-pointer field
+		_, err := tablemap.Convert[Source, Strict](src, nil)
+		assert.Error(t, err)
+
+		result, err := tablemap.Convert[Source, Strict](src, tablemap.DefaultOptions().WithCollectErrors(true))
+		assert.Error(t, err)
+		assert.Equal(t, []Strict{{Email: "alice@example.com"}}, result)
+	})
+}
@@ -1,17 +1,83 @@
 package csvmap
 
 import (
+	"context"
 	"encoding/csv"
+	"fmt"
 	"io"
+	"slices"
 
 	"github.com/kmio11/tablemap"
 )
 
+// Config defines the CSV dialect used by Reader and Writer.
+// It is kept separate from tablemap.Options, which controls how cells are mapped to struct fields.
+type Config struct {
+	// Delimiter is the field delimiter.
+	// Default is ','.
+	Delimiter rune
+	// Comment, if not 0, is the comment character.
+	// Lines beginning with it are ignored by the Reader, so the first
+	// non-comment line is read as the header.
+	Comment rune
+	// LazyQuotes allows quotes to appear in unquoted fields and
+	// non-doubled quotes in quoted fields when reading.
+	LazyQuotes bool
+	// TrimLeadingSpace ignores leading white space in a field when reading.
+	TrimLeadingSpace bool
+	// UseCRLF uses \r\n as the line terminator when writing.
+	UseCRLF bool
+	// SkipRows is the number of records to discard before the header row when reading.
+	// When set, records may have a variable number of fields, since preamble rows
+	// rarely match the header.
+	SkipRows int
+	// Headerless is for feeds without a header row.
+	// The Writer omits the header row, and the Reader maps columns by position
+	// to the fields of T in declaration order, or to tablemap.Options.Header if set.
+	Headerless bool
+	// PreambleLines are written verbatim by the Writer before the header, one per line,
+	// such as metadata lines like "# source=crm".
+	// The Reader skips them if they begin with Comment.
+	PreambleLines []string
+}
+
+// applyReader applies the config to the given csv.Reader.
+func (c *Config) applyReader(r *csv.Reader) {
+	if c == nil {
+		return
+	}
+	if c.Delimiter != 0 {
+		r.Comma = c.Delimiter
+	}
+	r.Comment = c.Comment
+	r.LazyQuotes = c.LazyQuotes
+	r.TrimLeadingSpace = c.TrimLeadingSpace
+	if c.SkipRows > 0 {
+		r.FieldsPerRecord = -1
+	}
+}
+
+// applyWriter applies the config to the given csv.Writer.
+func (c *Config) applyWriter(w *csv.Writer) {
+	if c == nil {
+		return
+	}
+	if c.Delimiter != 0 {
+		w.Comma = c.Delimiter
+	}
+	w.UseCRLF = c.UseCRLF
+}
+
 // Reader is a CSV reader that can unmarshal data into structs.
 type Reader[T any] struct {
-	R       *csv.Reader
-	opts    *tablemap.Options
-	handler *tablemap.RowHandler[T]
+	R          *csv.Reader
+	opts       *tablemap.Options
+	handler    *tablemap.RowHandler[T]
+	header     []string // header of the first input
+	current    []string // header of the current input, once read
+	headerRead bool     // whether the header of the current input has been checked by the handler
+	skipRows   int
+	headerless bool
 }
 
 // NewReader creates a new Reader with optional tablemap.Options.
@@ -22,30 +88,182 @@ func NewReader[T any](r io.Reader, opts *tablemap.Options) *Reader[T] {
 	}
 }
 
+// NewReaderConfig creates a new Reader with optional tablemap.Options,
+// applying the CSV dialect in cfg to the underlying csv.Reader.
+func NewReaderConfig[T any](r io.Reader, opts *tablemap.Options, cfg *Config) *Reader[T] {
+	reader := NewReader[T](r, opts)
+	cfg.applyReader(reader.R)
+	if cfg != nil {
+		reader.skipRows = cfg.SkipRows
+		reader.headerless = cfg.Headerless
+	}
+	return reader
+}
+
 // Read reads one record and converts it to struct T.
 // The first call to Read will read the header row.
 func (r *Reader[T]) Read() (*T, error) {
 	// Read header on first read
-	if r.handler == nil {
-		header, err := r.R.Read()
-		if err != nil {
+	if err := r.init(); err != nil {
+		return nil, err
+	}
+
+	// Read data row
+	row, err := r.R.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.handler.UnmarshalRow(row)
+}
+
+// Header returns the header row without consuming any data row.
+// The header is read on the first call and cached, so a following Read returns the first data row.
+// For a headerless Reader, it is the columns of T that the cells are mapped to.
+// It returns io.EOF if the input is empty.
+// This allows inspecting the columns before deciding how to decode the records.
+func (r *Reader[T]) Header() ([]string, error) {
+	header, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(header), nil
+}
+
+// readHeader reads the header row of the current input if not yet done
+func (r *Reader[T]) readHeader() ([]string, error) {
+	if r.current != nil {
+		return r.current, nil
+	}
+
+	// Discard preamble rows before the header
+	for i := 0; i < r.skipRows; i++ {
+		if _, err := r.R.Read(); err != nil {
 			return nil, err
 		}
+	}
 
-		handler, err := tablemap.NewRowHandler[T](header, r.opts)
+	if r.headerless {
+		header, err := r.positionalHeader()
 		if err != nil {
 			return nil, err
 		}
-		r.handler = handler
+		r.current = header
+		return r.current, nil
 	}
 
-	// Read data row
-	row, err := r.R.Read()
+	header, err := r.R.Read()
 	if err != nil {
 		return nil, err
 	}
+	r.current = slices.Clone(header)
+	return r.current, nil
+}
 
-	return r.handler.UnmarshalRow(row)
+// positionalHeader returns the columns that the cells of a headerless input are mapped to
+func (r *Reader[T]) positionalHeader() ([]string, error) {
+	handler, err := tablemap.NewRowHandler[T](nil, r.opts)
+	if err != nil {
+		return nil, err
+	}
+	return handler.Header(), nil
+}
+
+// init reads the header row and initializes the handler if not yet done.
+// After Reset, the header of the new input must match the first header.
+func (r *Reader[T]) init() error {
+	if r.headerRead {
+		return nil
+	}
+
+	header, err := r.readHeader()
+	if err != nil {
+		return err
+	}
+
+	if r.handler != nil {
+		if !slices.Equal(header, r.header) {
+			return fmt.Errorf("header %v differs from the first header %v", header, r.header)
+		}
+		r.headerRead = true
+		return nil
+	}
+
+	handler, err := tablemap.NewRowHandler[T](header, r.opts)
+	if err != nil {
+		return err
+	}
+	r.handler = handler
+	r.header = header
+	r.headerRead = true
+	return nil
+}
+
+// Reset switches the Reader to a new input, keeping the CSV dialect and the handler built from the first header.
+// The header of the new input is read on the next read and must match the first header.
+// This allows reading several files with the same layout, for example with ReadAllInto.
+// ReadAll does not use the handler, so it does not check the header against earlier inputs.
+func (r *Reader[T]) Reset(src io.Reader) {
+	old := r.R
+	r.R = csv.NewReader(src)
+	r.R.Comma = old.Comma
+	r.R.Comment = old.Comment
+	r.R.FieldsPerRecord = old.FieldsPerRecord
+	r.R.LazyQuotes = old.LazyQuotes
+	r.R.TrimLeadingSpace = old.TrimLeadingSpace
+	r.R.ReuseRecord = old.ReuseRecord
+	r.current = nil
+	r.headerRead = false
+}
+
+// Each reads the remaining records from CSV one by one and calls fn with each of them.
+// It stops at the end of the input, returning nil, or at the first error from reading or from fn,
+// returning that error. Unlike ReadAll, records are not kept, so memory use does not grow with the input.
+func (r *Reader[T]) Each(fn func(T) error) error {
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(*record); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAllContext reads all records from CSV one by one and converts them to a slice of struct T.
+// It checks ctx between records and returns ctx.Err() if the context is done.
+func (r *Reader[T]) ReadAllContext(ctx context.Context) ([]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []T
+	err := r.Each(func(record T) error {
+		result = append(result, record)
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReadAllInto reads all remaining records from CSV and appends them to dst.
+// Empty input appends nothing. On error, dst is left unchanged.
+func (r *Reader[T]) ReadAllInto(dst *[]T) error {
+	n := len(*dst)
+	err := r.Each(func(record T) error {
+		*dst = append(*dst, record)
+		return nil
+	})
+	if err != nil {
+		*dst = (*dst)[:n]
+	}
+	return err
 }
 
 // ReadAll reads all records from CSV and converts them to a slice of struct T.
@@ -56,7 +274,26 @@ func (r *Reader[T]) ReadAll() ([]T, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := tablemap.UnmarshalWithOptions(records[0], records[1:], &result, r.opts); err != nil {
+
+	// The header may already have been read by Header or Read
+	header := r.current
+	if header == nil {
+		// Discard preamble rows before the header
+		records = records[min(r.skipRows, len(records)):]
+		// Empty input has no header and no data
+		if len(records) == 0 {
+			return result, nil
+		}
+		if r.headerless {
+			header, err = r.positionalHeader()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			header, records = records[0], records[1:]
+		}
+	}
+	if err := tablemap.UnmarshalWithOptions(header, records, &result, r.opts); err != nil {
 		return nil, err
 	}
 
@@ -65,9 +302,14 @@ func (r *Reader[T]) ReadAll() ([]T, error) {
 
 // Writer is a CSV writer that can marshal structs into CSV format.
 type Writer[T any] struct {
-	W       *csv.Writer
-	opts    *tablemap.Options
-	handler *tablemap.RowHandler[T]
+	W          *csv.Writer
+	opts       *tablemap.Options
+	handler    *tablemap.RowHandler[T]
+	headerless bool
+	out        io.Writer // destination of W, for the preamble lines
+	preamble   []string  // preamble lines not yet written
+	started    bool      // whether the preamble and header have been written, or are already in the output
+	appending  bool      // whether the Writer appends to existing data, whose header is the handler's
 }
 
 // NewWriter creates a new Writer with optional tablemap.Options.
@@ -75,29 +317,53 @@ func NewWriter[T any](w io.Writer, opts *tablemap.Options) *Writer[T] {
 	return &Writer[T]{
 		W:    csv.NewWriter(w),
 		opts: opts,
+		out:  w,
 	}
 }
 
+// NewWriterConfig creates a new Writer with optional tablemap.Options,
+// applying the CSV dialect in cfg to the underlying csv.Writer.
+func NewWriterConfig[T any](w io.Writer, opts *tablemap.Options, cfg *Config) *Writer[T] {
+	writer := NewWriter[T](w, opts)
+	cfg.applyWriter(writer.W)
+	if cfg != nil {
+		writer.headerless = cfg.Headerless
+		writer.preamble = cfg.PreambleLines
+	}
+	return writer
+}
+
+// NewAppendWriter creates a new Writer with optional tablemap.Options for appending records
+// to existing CSV data with the given header, such as a file opened with os.O_APPEND.
+// The header is not written, and records are written in the column order of header.
+// It is an error for header to contain a column that does not map to a field of T.
+func NewAppendWriter[T any](w io.Writer, header []string, opts *tablemap.Options) (*Writer[T], error) {
+	handler, err := tablemap.NewRowHandler[T](header, opts)
+	if err != nil {
+		return nil, err
+	}
+	if unmapped := handler.UnmappedColumns(); len(unmapped) > 0 {
+		return nil, fmt.Errorf("unknown column: %s", unmapped[0])
+	}
+
+	writer := NewWriter[T](w, opts)
+	writer.handler = handler
+	writer.started = true
+	writer.appending = true
+	return writer, nil
+}
+
 // Write writes a single record to CSV.
-// The first call to Write will write the header row.
+// The first call to Write will write the header row, unless the Writer is headerless.
+// The record is skipped if it is all zero and Options.OmitZeroRows is set.
+// Call Flush after the last Write to make sure all data is written.
 func (w *Writer[T]) Write(data T) error {
 	// Initialize handler and write header on first write
-	if w.handler == nil {
-		var zero T
-		header, _, err := tablemap.MarshalWithOptions([]T{zero}, w.opts)
-		if err != nil {
-			return err
-		}
-
-		handler, err := tablemap.NewRowHandler[T](header, w.opts)
-		if err != nil {
-			return err
-		}
-		w.handler = handler
-
-		if err := w.W.Write(header); err != nil {
-			return err
-		}
+	if err := w.init(); err != nil {
+		return err
+	}
+	if w.handler.OmitRow(&data) {
+		return nil
 	}
 
 	// Write data row
@@ -110,15 +376,135 @@ func (w *Writer[T]) Write(data T) error {
 		return err
 	}
 
+	return w.W.Error()
+}
+
+// WriteHeader writes the header row built from the fields of T.
+// Later calls to Write and WriteHeader do not write the header again,
+// so it can be used to emit a header even when there are no records.
+// Call Flush afterwards to make sure the header is written.
+// It writes nothing if the Writer is headerless.
+func (w *Writer[T]) WriteHeader() error {
+	return w.init()
+}
+
+// init initializes the handler and writes the header row if not yet done.
+// The handler of an append Writer is known from the start, but its header is never written.
+func (w *Writer[T]) init() error {
+	if w.started {
+		return nil
+	}
+
+	if w.handler == nil {
+		handler, err := tablemap.NewRowHandler[T](nil, w.opts)
+		if err != nil {
+			return err
+		}
+		w.handler = handler
+	}
+	w.started = true
+
+	if err := w.writePreamble(); err != nil {
+		return err
+	}
+	if w.headerless {
+		return nil
+	}
+	if err := w.W.Write(w.handler.Header()); err != nil {
+		return err
+	}
+	return w.W.Error()
+}
+
+// writePreamble writes the preamble lines, if not yet written.
+// It is called before anything else is written, so nothing is buffered in W yet.
+func (w *Writer[T]) writePreamble() error {
+	eol := "\n"
+	if w.W.UseCRLF {
+		eol = "\r\n"
+	}
+	for _, line := range w.preamble {
+		if _, err := io.WriteString(w.out, line+eol); err != nil {
+			return err
+		}
+	}
+	w.preamble = nil
 	return nil
 }
 
+// Flush writes any buffered data to the underlying io.Writer
+// and reports any error that occurred during a previous Write or Flush.
+// Write does not flush, so Flush must be called after the last Write.
+func (w *Writer[T]) Flush() error {
+	w.W.Flush()
+	return w.W.Error()
+}
+
 // WriteAll writes a slice of struct T as CSV data.
+// The header row is written unless it has already been written by an earlier call, or the Writer
+// is headerless or appends to existing data. For an append Writer, the records are written in the
+// column order of its header.
+// WriteAll flushes the underlying csv.Writer, so there is no need to call Flush afterwards.
 func (w *Writer[T]) WriteAll(data []T) error {
 	defer w.W.Flush()
-	header, rows, err := tablemap.MarshalWithOptions(data, w.opts)
+
+	var rows [][]string
+	var err error
+	if w.appending {
+		_, rows, err = tablemap.MarshalOrdered(data, w.handler.Header(), w.opts)
+	} else {
+		_, rows, err = tablemap.MarshalWithOptions(data, w.opts)
+	}
 	if err != nil {
 		return err
 	}
-	return w.W.WriteAll(append([][]string{header}, rows...))
+
+	if err := w.init(); err != nil {
+		return err
+	}
+	return w.W.WriteAll(rows)
+}
+
+// WriteAllContext writes a slice of struct T as CSV data one record at a time.
+// It checks ctx between records and returns ctx.Err() if the context is done.
+// The header row is written even if data is empty, unless the Writer is headerless.
+// WriteAllContext flushes the underlying csv.Writer, so there is no need to call Flush afterwards.
+func (w *Writer[T]) WriteAllContext(ctx context.Context, data []T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := w.init(); err != nil {
+		return err
+	}
+
+	for _, record := range data {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// WriteFiltered writes the records of data for which keep returns true, one record at a time.
+// The header row is written even if no record is kept, unless the Writer is headerless.
+// WriteFiltered flushes the underlying csv.Writer, so there is no need to call Flush afterwards.
+func (w *Writer[T]) WriteFiltered(data []T, keep func(T) bool) error {
+	if err := w.init(); err != nil {
+		return err
+	}
+
+	for _, record := range data {
+		if !keep(record) {
+			continue
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
 }
@@ -2,7 +2,10 @@ package csvmap_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
+	"strings"
 	"testing"
 	"text/template"
 	"time"
@@ -328,6 +331,15 @@ func TestWriter(t *testing.T) {
 	}
 }
 
+func TestWriter_empty(t *testing.T) {
+	var buf bytes.Buffer
+	writer := csvmap.NewWriter[TestStruct](&buf, nil)
+
+	err := writer.WriteAll([]TestStruct{})
+	assert.NoError(t, err)
+	assert.Equal(t, "string,int,time\n", buf.String())
+}
+
 func TestWriter_nil_options(t *testing.T) {
 	now := time.Now().UTC().Truncate(time.Second)
 
@@ -528,7 +540,7 @@ func TestWriter_Write(t *testing.T) {
 				err := writer.Write(record)
 				assert.NoError(t, err)
 			}
-			writer.W.Flush()
+			assert.NoError(t, writer.Flush())
 
 			var expected bytes.Buffer
 			err := csvTemplate.Execute(&expected, tt.expected)
@@ -538,3 +550,841 @@ func TestWriter_Write(t *testing.T) {
 		})
 	}
 }
+
+func TestReaderConfig(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *csvmap.Config
+		input    string
+		expected []Record
+	}{
+		{
+			name:  "nil config",
+			cfg:   nil,
+			input: "name,age\nAlice,23\n",
+			expected: []Record{
+				{Name: "Alice", Age: 23},
+			},
+		},
+		{
+			name:  "semicolon delimiter",
+			cfg:   &csvmap.Config{Delimiter: ';'},
+			input: "name;age\nAlice,Bob;23\n",
+			expected: []Record{
+				{Name: "Alice,Bob", Age: 23},
+			},
+		},
+		{
+			name:  "comment lines",
+			cfg:   &csvmap.Config{Comment: '#'},
+			input: "name,age\n# comment\nAlice,23\n",
+			expected: []Record{
+				{Name: "Alice", Age: 23},
+			},
+		},
+		{
+			name:  "lazy quotes",
+			cfg:   &csvmap.Config{LazyQuotes: true},
+			input: "name,age\n5\" disk,23\n",
+			expected: []Record{
+				{Name: `5" disk`, Age: 23},
+			},
+		},
+		{
+			name:  "trim leading space",
+			cfg:   &csvmap.Config{TrimLeadingSpace: true},
+			input: "name, age\nAlice, 23\n",
+			expected: []Record{
+				{Name: "Alice", Age: 23},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := csvmap.NewReaderConfig[Record](strings.NewReader(tt.input), nil, tt.cfg)
+			result, err := reader.ReadAll()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestWriterConfig(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	input := []Record{
+		{Name: "Alice", Age: 23},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *csvmap.Config
+		expected string
+	}{
+		{
+			name:     "nil config",
+			cfg:      nil,
+			expected: "name,age\nAlice,23\n",
+		},
+		{
+			name:     "pipe delimiter",
+			cfg:      &csvmap.Config{Delimiter: '|'},
+			expected: "name|age\nAlice|23\n",
+		},
+		{
+			name:     "CRLF",
+			cfg:      &csvmap.Config{UseCRLF: true},
+			expected: "name,age\r\nAlice,23\r\n",
+		},
+		{
+			name:     "headerless",
+			cfg:      &csvmap.Config{Delimiter: '|', Headerless: true},
+			expected: "Alice|23\n",
+		},
+		{
+			name:     "preamble",
+			cfg:      &csvmap.Config{PreambleLines: []string{"# source=crm", "# rows=1"}},
+			expected: "# source=crm\n# rows=1\nname,age\nAlice,23\n",
+		},
+		{
+			name:     "preamble with CRLF",
+			cfg:      &csvmap.Config{UseCRLF: true, PreambleLines: []string{"# source=crm"}},
+			expected: "# source=crm\r\nname,age\r\nAlice,23\r\n",
+		},
+		{
+			name:     "headerless preamble",
+			cfg:      &csvmap.Config{Headerless: true, PreambleLines: []string{"# source=crm"}},
+			expected: "# source=crm\nAlice,23\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := csvmap.NewWriterConfig[Record](&buf, nil, tt.cfg)
+			err := writer.WriteAll(input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriter_WriteHeader(t *testing.T) {
+	t.Run("no records", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+
+		assert.NoError(t, writer.WriteHeader())
+		assert.NoError(t, writer.Flush())
+		assert.Equal(t, "string,int,time\n", buf.String())
+	})
+
+	t.Run("headerless", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriterConfig[TestStruct](&buf, nil, &csvmap.Config{Headerless: true})
+
+		assert.NoError(t, writer.WriteHeader())
+		assert.NoError(t, writer.Write(TestStruct{String: "test1", Int: 123}))
+		assert.NoError(t, writer.Flush())
+		assert.Equal(t, "test1,123,0001-01-01T00:00:00Z\n", buf.String())
+	})
+
+	t.Run("header is not duplicated", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+
+		assert.NoError(t, writer.WriteHeader())
+		assert.NoError(t, writer.WriteHeader())
+		assert.NoError(t, writer.Write(TestStruct{String: "test1", Int: 123}))
+		assert.NoError(t, writer.Flush())
+		assert.Equal(t, "string,int,time\ntest1,123,0001-01-01T00:00:00Z\n", buf.String())
+	})
+
+	t.Run("followed by WriteAll", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+
+		assert.NoError(t, writer.WriteHeader())
+		assert.NoError(t, writer.WriteAll([]TestStruct{{String: "test1", Int: 123}}))
+		assert.Equal(t, "string,int,time\ntest1,123,0001-01-01T00:00:00Z\n", buf.String())
+	})
+
+	t.Run("Write followed by WriteAll", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+
+		assert.NoError(t, writer.Write(TestStruct{String: "test1", Int: 123}))
+		assert.NoError(t, writer.WriteAll([]TestStruct{{String: "test2", Int: 456}}))
+		assert.Equal(t, "string,int,time\ntest1,123,0001-01-01T00:00:00Z\ntest2,456,0001-01-01T00:00:00Z\n", buf.String())
+	})
+}
+
+func TestWriter_Flush(t *testing.T) {
+	t.Run("flush error", func(t *testing.T) {
+		writer := csvmap.NewWriter[TestStruct](errWriter{}, nil)
+
+		// Write is buffered, so the error is reported by Flush
+		err := writer.Write(TestStruct{String: "test1", Int: 123})
+		assert.NoError(t, err)
+
+		err = writer.Flush()
+		assert.EqualError(t, err, "write failed")
+
+		// Subsequent writes report the earlier error
+		err = writer.Write(TestStruct{String: "test2", Int: 456})
+		assert.Error(t, err)
+	})
+
+	t.Run("flush success", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+
+		err := writer.Write(TestStruct{String: "test1", Int: 123})
+		assert.NoError(t, err)
+		assert.Empty(t, buf.String())
+
+		err = writer.Flush()
+		assert.NoError(t, err)
+		assert.Equal(t, "string,int,time\ntest1,123,0001-01-01T00:00:00Z\n", buf.String())
+	})
+}
+
+func TestReader_ReadAllContext(t *testing.T) {
+	input := "string,int,time\ntest1,123,2024-01-01T00:00:00Z\ntest2,456,2024-01-02T00:00:00Z\n"
+
+	t.Run("success", func(t *testing.T) {
+		reader := csvmap.NewReader[TestStruct](strings.NewReader(input), nil)
+		result, err := reader.ReadAllContext(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "test1", result[0].String)
+		assert.Equal(t, 456, result[1].Int)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		reader := csvmap.NewReader[TestStruct](strings.NewReader(""), nil)
+		result, err := reader.ReadAllContext(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		reader := csvmap.NewReader[TestStruct](strings.NewReader(input), nil)
+		result, err := reader.ReadAllContext(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, result)
+	})
+}
+
+func TestReader_Each(t *testing.T) {
+	input := "string,int,time\ntest1,123,2024-01-01T00:00:00Z\ntest2,456,2024-01-02T00:00:00Z\n"
+
+	t.Run("all records", func(t *testing.T) {
+		reader := csvmap.NewReader[TestStruct](strings.NewReader(input), nil)
+		var names []string
+		err := reader.Each(func(record TestStruct) error {
+			names = append(names, record.String)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"test1", "test2"}, names)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		reader := csvmap.NewReader[TestStruct](strings.NewReader(""), nil)
+		called := false
+		err := reader.Each(func(TestStruct) error {
+			called = true
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.False(t, called)
+	})
+
+	t.Run("callback error stops", func(t *testing.T) {
+		errStop := errors.New("stop")
+		reader := csvmap.NewReader[TestStruct](strings.NewReader(input), nil)
+		count := 0
+		err := reader.Each(func(TestStruct) error {
+			count++
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, count)
+
+		// The remaining records can still be read
+		record, err := reader.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, "test2", record.String)
+	})
+
+	t.Run("invalid record", func(t *testing.T) {
+		reader := csvmap.NewReader[TestStruct](strings.NewReader("string,int,time\ntest1,abc,\n"), nil)
+		err := reader.Each(func(TestStruct) error { return nil })
+		assert.Error(t, err)
+	})
+}
+
+func TestWriter_WriteAllContext(t *testing.T) {
+	input := []TestStruct{
+		{String: "test1", Int: 123},
+		{String: "test2", Int: 456},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+		err := writer.WriteAllContext(context.Background(), input)
+		assert.NoError(t, err)
+		assert.Equal(t, "string,int,time\ntest1,123,0001-01-01T00:00:00Z\ntest2,456,0001-01-01T00:00:00Z\n", buf.String())
+	})
+
+	t.Run("empty data", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+		err := writer.WriteAllContext(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "string,int,time\n", buf.String())
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+		err := writer.WriteAllContext(ctx, input)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestWriter_WriteFiltered(t *testing.T) {
+	input := []TestStruct{
+		{String: "test1", Int: 123},
+		{String: "test2", Int: 456},
+		{String: "test3", Int: 789},
+	}
+
+	t.Run("kept records", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+		err := writer.WriteFiltered(input, func(s TestStruct) bool { return s.Int != 456 })
+		assert.NoError(t, err)
+		assert.Equal(t, "string,int,time\ntest1,123,0001-01-01T00:00:00Z\ntest3,789,0001-01-01T00:00:00Z\n", buf.String())
+	})
+
+	t.Run("no records kept", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriter[TestStruct](&buf, nil)
+		err := writer.WriteFiltered(input, func(TestStruct) bool { return false })
+		assert.NoError(t, err)
+		assert.Equal(t, "string,int,time\n", buf.String())
+	})
+
+	t.Run("headerless", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriterConfig[TestStruct](&buf, nil, &csvmap.Config{Headerless: true})
+		err := writer.WriteFiltered(input, func(s TestStruct) bool { return s.Int == 456 })
+		assert.NoError(t, err)
+		assert.Equal(t, "test2,456,0001-01-01T00:00:00Z\n", buf.String())
+	})
+}
+
+func TestWriter_omitZeroRows(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	opts := tablemap.DefaultOptions().WithOmitZeroRows(true)
+	input := []Record{{Name: "Alice", Age: 23}, {}, {Age: 25}}
+	const expected = "name,age\nAlice,23\n,25\n"
+
+	writes := map[string]func(w *csvmap.Writer[Record]) error{
+		"Write": func(w *csvmap.Writer[Record]) error {
+			for _, record := range input {
+				if err := w.Write(record); err != nil {
+					return err
+				}
+			}
+			return w.Flush()
+		},
+		"WriteAll": func(w *csvmap.Writer[Record]) error {
+			return w.WriteAll(input)
+		},
+		"WriteAllContext": func(w *csvmap.Writer[Record]) error {
+			return w.WriteAllContext(context.Background(), input)
+		},
+		"WriteFiltered": func(w *csvmap.Writer[Record]) error {
+			return w.WriteFiltered(input, func(Record) bool { return true })
+		},
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, write(csvmap.NewWriter[Record](&buf, opts)))
+			assert.Equal(t, expected, buf.String())
+		})
+	}
+}
+
+func TestReader_ReadAllInto(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	t.Run("multiple inputs", func(t *testing.T) {
+		dst := []Record{{Name: "existing", Age: 1}}
+
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader("name;age\nAlice;23\n"), nil, &csvmap.Config{Delimiter: ';'})
+		assert.NoError(t, reader.ReadAllInto(&dst))
+
+		// The dialect is kept across inputs
+		reader.Reset(strings.NewReader("name;age\nBob;25\nCharlie;27\n"))
+		assert.NoError(t, reader.ReadAllInto(&dst))
+
+		// Empty input appends nothing
+		reader.Reset(strings.NewReader(""))
+		assert.NoError(t, reader.ReadAllInto(&dst))
+
+		assert.Equal(t, []Record{
+			{Name: "existing", Age: 1},
+			{Name: "Alice", Age: 23},
+			{Name: "Bob", Age: 25},
+			{Name: "Charlie", Age: 27},
+		}, dst)
+	})
+
+	t.Run("header differs", func(t *testing.T) {
+		var dst []Record
+
+		reader := csvmap.NewReader[Record](strings.NewReader("name,age\nAlice,23\n"), nil)
+		assert.NoError(t, reader.ReadAllInto(&dst))
+
+		reader.Reset(strings.NewReader("age,name\n25,Bob\n"))
+		err := reader.ReadAllInto(&dst)
+		assert.EqualError(t, err, "header [age name] differs from the first header [name age]")
+		assert.Equal(t, []Record{{Name: "Alice", Age: 23}}, dst)
+	})
+
+	t.Run("error leaves dst unchanged", func(t *testing.T) {
+		dst := []Record{{Name: "existing", Age: 1}}
+
+		reader := csvmap.NewReader[Record](strings.NewReader("name,age\nAlice,23\nBob,abc\n"), nil)
+		err := reader.ReadAllInto(&dst)
+		assert.Error(t, err)
+		assert.Equal(t, []Record{{Name: "existing", Age: 1}}, dst)
+	})
+}
+
+func TestReader_Header(t *testing.T) {
+	const input = "string,int,time\ntest1,123,2024-01-01T00:00:00Z\ntest2,456,2024-01-02T00:00:00Z\n"
+
+	t.Run("then Read", func(t *testing.T) {
+		reader := csvmap.NewReader[TestStruct](strings.NewReader(input), nil)
+
+		header, err := reader.Header()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"string", "int", "time"}, header)
+
+		// The header is cached
+		header, err = reader.Header()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"string", "int", "time"}, header)
+
+		record, err := reader.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, "test1", record.String)
+	})
+
+	t.Run("then ReadAll", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[TestStruct](strings.NewReader("# preamble\n"+input), nil, &csvmap.Config{SkipRows: 1})
+
+		_, err := reader.Header()
+		assert.NoError(t, err)
+
+		result, err := reader.ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "test2", result[1].String)
+	})
+
+	t.Run("before the element type is known", func(t *testing.T) {
+		// The handler is not built by Header, so any type can inspect the columns
+		reader := csvmap.NewReader[int](strings.NewReader(input), nil)
+		header, err := reader.Header()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"string", "int", "time"}, header)
+	})
+
+	t.Run("takes precedence over Options.Header", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithHeader([]string{"int", "string"})
+		const input = "string,int\ntest1,123\n"
+		expected := TestStruct{String: "test1", Int: 123}
+
+		result, err := csvmap.NewReader[TestStruct](strings.NewReader(input), opts).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, []TestStruct{expected}, result)
+
+		record, err := csvmap.NewReader[TestStruct](strings.NewReader(input), opts).Read()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, *record)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		reader := csvmap.NewReader[TestStruct](strings.NewReader(""), nil)
+		_, err := reader.Header()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+}
+
+func TestReader_ReadAll_empty(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "empty input",
+			input: "",
+		},
+		{
+			name:  "header only",
+			input: "string,int,time\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := csvmap.NewReader[TestStruct](strings.NewReader(tt.input), nil)
+			result, err := reader.ReadAll()
+			assert.NoError(t, err)
+			assert.Empty(t, result)
+		})
+	}
+}
+
+func TestReaderConfig_CommentPreamble(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	const input = "# generated at 2024-01-01T00:00:00Z\n# source: example\nname,age\nAlice,23\n# trailing note\nBob,25\n"
+	cfg := &csvmap.Config{Comment: '#'}
+	expected := []Record{{Name: "Alice", Age: 23}, {Name: "Bob", Age: 25}}
+
+	t.Run("ReadAll", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader(input), nil, cfg)
+		result, err := reader.ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		// The first non-comment line becomes the header on the lazy header read
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader(input), nil, cfg)
+		result, err := reader.ReadAllContext(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader(input), nil, cfg)
+		header, err := reader.Header()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name", "age"}, header)
+	})
+
+	t.Run("comments only", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader("# nothing here\n"), nil, cfg)
+		result, err := reader.ReadAll()
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
+
+func TestWriterConfig_PreambleLines(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	input := []Record{{Name: "Alice", Age: 23}, {Name: "Bob", Age: 25}}
+	cfg := &csvmap.Config{Comment: '#', PreambleLines: []string{"# source=crm", "# exported=2024-01-01"}}
+
+	var buf bytes.Buffer
+	writer := csvmap.NewWriterConfig[Record](&buf, nil, cfg)
+	for _, record := range input {
+		assert.NoError(t, writer.Write(record))
+	}
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "# source=crm\n# exported=2024-01-01\nname,age\nAlice,23\nBob,25\n", buf.String())
+
+	// The preamble is skipped as comments on the way back
+	reader := csvmap.NewReaderConfig[Record](&buf, nil, cfg)
+	result, err := reader.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+}
+
+func TestReaderConfig_SkipRows(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	input := "exported by tool\nversion,1.0,beta\nname,age\nAlice,23\nBob,25\n"
+	expected := []Record{
+		{Name: "Alice", Age: 23},
+		{Name: "Bob", Age: 25},
+	}
+	cfg := &csvmap.Config{SkipRows: 2}
+
+	t.Run("ReadAll", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader(input), nil, cfg)
+		result, err := reader.ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader(input), nil, cfg)
+		var result []Record
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			result = append(result, *record)
+		}
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("fewer rows than SkipRows", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader("preamble\n"), nil, cfg)
+		result, err := reader.ReadAll()
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+
+		reader = csvmap.NewReaderConfig[Record](strings.NewReader("preamble\n"), nil, cfg)
+		_, err = reader.Read()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestReaderConfig_Headerless(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	input := "Alice,23\nBob,25\n"
+	expected := []Record{
+		{Name: "Alice", Age: 23},
+		{Name: "Bob", Age: 25},
+	}
+	cfg := &csvmap.Config{Headerless: true}
+
+	t.Run("ReadAll", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader(input), nil, cfg)
+		result, err := reader.ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader(input), nil, cfg)
+		var result []Record
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			result = append(result, *record)
+		}
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader(input), nil, cfg)
+		header, err := reader.Header()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name", "age"}, header)
+
+		result, err := reader.ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Options.Header sets the column order", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithHeader([]string{"age", "name"})
+		reader := csvmap.NewReaderConfig[Record](strings.NewReader("23,Alice\n"), opts, cfg)
+		result, err := reader.ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{{Name: "Alice", Age: 23}}, result)
+	})
+
+	t.Run("append to existing file", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, csvmap.NewWriter[Record](&buf, nil).WriteAll(expected[:1]))
+
+		writer := csvmap.NewWriterConfig[Record](&buf, nil, cfg)
+		assert.NoError(t, writer.Write(expected[1]))
+		assert.NoError(t, writer.Flush())
+		assert.Equal(t, "name,age\nAlice,23\nBob,25\n", buf.String())
+
+		result, err := csvmap.NewReader[Record](&buf, nil).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := csvmap.NewWriterConfig[Record](&buf, nil, cfg)
+		assert.NoError(t, writer.WriteAll(expected))
+		assert.Equal(t, input, buf.String())
+
+		reader := csvmap.NewReaderConfig[Record](&buf, nil, cfg)
+		result, err := reader.ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+}
+
+func TestNewAutoReader(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		City string `table:"city"`
+	}
+
+	tests := []struct {
+		name          string
+		input         string
+		candidates    []rune
+		expectedComma rune
+		expected      []Record
+	}{
+		{
+			name:          "comma",
+			input:         "name,city\nAlice,Tokyo\n",
+			expectedComma: ',',
+			expected:      []Record{{Name: "Alice", City: "Tokyo"}},
+		},
+		{
+			name:          "tab",
+			input:         "name\tcity\nAlice, Jr.\tTokyo\n",
+			expectedComma: '\t',
+			expected:      []Record{{Name: "Alice, Jr.", City: "Tokyo"}},
+		},
+		{
+			name:          "semicolon",
+			input:         "name;city\nAlice;Tokyo\n",
+			expectedComma: ';',
+			expected:      []Record{{Name: "Alice", City: "Tokyo"}},
+		},
+		{
+			name:          "delimiters in quoted header",
+			input:         "name;\"note, with, commas\"\nAlice;x\n",
+			expectedComma: ';',
+			expected:      []Record{{Name: "Alice"}},
+		},
+		{
+			name:          "custom candidates",
+			input:         "name|city\nAlice|Tokyo\n",
+			candidates:    []rune{',', '|'},
+			expectedComma: '|',
+			expected:      []Record{{Name: "Alice", City: "Tokyo"}},
+		},
+		{
+			name:          "single column",
+			input:         "name\nAlice\n",
+			expectedComma: ',',
+			expected:      []Record{{Name: "Alice"}},
+		},
+		{
+			name:          "no trailing newline",
+			input:         "name;city",
+			expectedComma: ';',
+			expected:      nil,
+		},
+		{
+			name:          "empty input",
+			input:         "",
+			expectedComma: ',',
+			expected:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := csvmap.NewAutoReader[Record](strings.NewReader(tt.input), nil, tt.candidates...)
+			assert.Equal(t, tt.expectedComma, reader.R.Comma)
+
+			result, err := reader.ReadAll()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNewAppendWriter(t *testing.T) {
+	input := []TestStruct{
+		{String: "test2", Int: 456},
+		{String: "test3", Int: 789},
+	}
+
+	t.Run("write", func(t *testing.T) {
+		buf := bytes.NewBufferString("int,string,time\n123,test1,0001-01-01T00:00:00Z\n")
+		writer, err := csvmap.NewAppendWriter[TestStruct](buf, []string{"int", "string", "time"}, nil)
+		assert.NoError(t, err)
+
+		for _, record := range input {
+			assert.NoError(t, writer.Write(record))
+		}
+		assert.NoError(t, writer.WriteHeader())
+		assert.NoError(t, writer.Flush())
+		assert.Equal(t, "int,string,time\n123,test1,0001-01-01T00:00:00Z\n456,test2,0001-01-01T00:00:00Z\n789,test3,0001-01-01T00:00:00Z\n", buf.String())
+
+		// The appended records read back with the existing header
+		result, err := csvmap.NewReader[TestStruct](buf, nil).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, result, 3)
+	})
+
+	t.Run("write all", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := csvmap.NewAppendWriter[TestStruct](&buf, []string{"string", "int"}, nil)
+		assert.NoError(t, err)
+
+		assert.NoError(t, writer.WriteAll(input))
+		assert.Equal(t, "test2,456\ntest3,789\n", buf.String())
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := csvmap.NewAppendWriter[TestStruct](io.Discard, []string{"string", "phone"}, nil)
+		assert.EqualError(t, err, "unknown column: phone")
+	})
+}
@@ -110,7 +110,10 @@ func ExampleWriter_Write() {
 			return
 		}
 	}
-	writer.W.Flush()
+	if err := writer.Flush(); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
 	fmt.Println(buf.String())
 	// Output:
@@ -0,0 +1,55 @@
+package csvmap
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/kmio11/tablemap"
+)
+
+// defaultDelimiters are the delimiters NewAutoReader chooses from by default
+var defaultDelimiters = []rune{',', '\t', ';'}
+
+// NewAutoReader creates a new Reader with optional tablemap.Options, detecting the delimiter
+// from the first line of r. The candidate that occurs most often in the first line,
+// outside quoted fields, is used. Ties go to the earlier candidate, and the first candidate
+// is used if none occurs. candidates defaults to comma, tab and semicolon.
+// The chosen delimiter is available as R.Comma.
+func NewAutoReader[T any](r io.Reader, opts *tablemap.Options, candidates ...rune) *Reader[T] {
+	if len(candidates) == 0 {
+		candidates = defaultDelimiters
+	}
+
+	// Read the first line ahead, and put it back in front of the rest of the input.
+	// A read error is returned again by the first read of the Reader.
+	br := bufio.NewReader(r)
+	line, _ := br.ReadString('\n')
+
+	reader := NewReader[T](io.MultiReader(strings.NewReader(line), br), opts)
+	reader.R.Comma = sniffDelimiter(line, candidates)
+	return reader
+}
+
+// sniffDelimiter returns the candidate occurring most often in line outside quoted fields
+func sniffDelimiter(line string, candidates []rune) rune {
+	counts := make(map[rune]int, len(candidates))
+	quoted := false
+	for _, c := range line {
+		if c == '"' {
+			quoted = !quoted
+			continue
+		}
+		if !quoted {
+			counts[c]++
+		}
+	}
+
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if counts[c] > counts[best] {
+			best = c
+		}
+	}
+	return best
+}
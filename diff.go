@@ -0,0 +1,152 @@
+package tablemap
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// TableDiff describes the differences between two tables sharing a header.
+type TableDiff struct {
+	// Added holds the rows of b whose key is not in a, in the order of b.
+	Added [][]string
+	// Removed holds the rows of a whose key is not in b, in the order of a.
+	Removed [][]string
+	// Changed holds the rows whose key is in both tables with different cells, in the order of b.
+	Changed []RowChange
+}
+
+// RowChange describes a row present in both tables of a Diff with different cells.
+type RowChange struct {
+	Key     string
+	Before  []string // The row in a
+	After   []string // The row in b
+	Columns []string // The columns whose cells differ, in header order
+}
+
+// Empty reports whether the tables have no differences.
+func (d *TableDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff compares the rows of a and b by the cell in keyColumn, reporting the rows
+// added in b, the rows removed from a and the rows whose cells changed.
+// Both tables must have the layout of header, and their keys must be unique.
+func Diff(header []string, a, b [][]string, keyColumn string) (*TableDiff, error) {
+	key := slices.Index(header, keyColumn)
+	if key < 0 {
+		return nil, fmt.Errorf("key column not in header: %s", keyColumn)
+	}
+
+	rowsA, err := indexRows(header, a, key, "a")
+	if err != nil {
+		return nil, err
+	}
+	rowsB, err := indexRows(header, b, key, "b")
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &TableDiff{}
+	for _, row := range a {
+		if _, ok := rowsB[row[key]]; !ok {
+			diff.Removed = append(diff.Removed, row)
+		}
+	}
+	for _, row := range b {
+		before, ok := rowsA[row[key]]
+		if !ok {
+			diff.Added = append(diff.Added, row)
+			continue
+		}
+		var columns []string
+		for i, name := range header {
+			if before[i] != row[i] {
+				columns = append(columns, name)
+			}
+		}
+		if columns != nil {
+			diff.Changed = append(diff.Changed, RowChange{
+				Key:     row[key],
+				Before:  before,
+				After:   row,
+				Columns: columns,
+			})
+		}
+	}
+
+	return diff, nil
+}
+
+// indexRows maps the key cell of each row of a table to the row
+func indexRows(header []string, data [][]string, key int, name string) (map[string][]string, error) {
+	rows := make(map[string][]string, len(data))
+	for i, row := range data {
+		if len(row) != len(header) {
+			return nil, fmt.Errorf("%s: row %d: inconsistent data length", name, i)
+		}
+		if _, ok := rows[row[key]]; ok {
+			return nil, fmt.Errorf("%s: row %d: duplicate key: %s", name, i, row[key])
+		}
+		rows[row[key]] = row
+	}
+	return rows, nil
+}
+
+// MarshalDiff marshals two values of the same struct type and returns the columns
+// whose cells differ, along with the cells of before and after for those columns, in column order.
+// before and after must be structs or pointers to structs.
+func MarshalDiff(before, after any, opts *Options) ([]string, []string, []string, error) {
+	beforeVal, err := structValue(before)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	afterVal, err := structValue(after)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if beforeVal.Type() != afterVal.Type() {
+		return nil, nil, nil, fmt.Errorf("values must have the same type, got %v and %v", beforeVal.Type(), afterVal.Type())
+	}
+
+	r, err := newRow(beforeVal.Type(), nil, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	beforeRow, err := r.marshalRow(beforeVal.Interface())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	afterRow, err := r.marshalRow(afterVal.Interface())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var columns, beforeCells, afterCells []string
+	for i, name := range r.header {
+		if beforeRow[i] != afterRow[i] {
+			columns = append(columns, name)
+			beforeCells = append(beforeCells, beforeRow[i])
+			afterCells = append(afterCells, afterRow[i])
+		}
+	}
+	return columns, beforeCells, afterCells, nil
+}
+
+// structValue dereferences v until reaching a struct
+func structValue(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return reflect.Value{}, fmt.Errorf("v must not be nil")
+	}
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}, fmt.Errorf("v must not be nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("v must be a struct or pointer to struct, got %v", rv.Kind())
+	}
+	return rv, nil
+}
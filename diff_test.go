@@ -0,0 +1,181 @@
+package tablemap_test
+
+import (
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	header := []string{"id", "name", "age"}
+
+	tests := []struct {
+		name     string
+		a        [][]string
+		b        [][]string
+		key      string
+		expected *tablemap.TableDiff
+		wantErr  string
+	}{
+		{
+			name: "added, removed and changed",
+			a: [][]string{
+				{"1", "Alice", "23"},
+				{"2", "Bob", "45"},
+				{"3", "Carol", "31"},
+			},
+			b: [][]string{
+				{"3", "Carol", "32"},
+				{"1", "Alice", "23"},
+				{"4", "Dave", "19"},
+			},
+			key: "id",
+			expected: &tablemap.TableDiff{
+				Added:   [][]string{{"4", "Dave", "19"}},
+				Removed: [][]string{{"2", "Bob", "45"}},
+				Changed: []tablemap.RowChange{
+					{
+						Key:     "3",
+						Before:  []string{"3", "Carol", "31"},
+						After:   []string{"3", "Carol", "32"},
+						Columns: []string{"age"},
+					},
+				},
+			},
+		},
+		{
+			name:     "equal tables",
+			a:        [][]string{{"1", "Alice", "23"}},
+			b:        [][]string{{"1", "Alice", "23"}},
+			key:      "id",
+			expected: &tablemap.TableDiff{},
+		},
+		{
+			name: "key is not the first column",
+			a:    [][]string{{"1", "Alice", "23"}},
+			b:    [][]string{{"2", "Alice", "24"}},
+			key:  "name",
+			expected: &tablemap.TableDiff{
+				Changed: []tablemap.RowChange{
+					{
+						Key:     "Alice",
+						Before:  []string{"1", "Alice", "23"},
+						After:   []string{"2", "Alice", "24"},
+						Columns: []string{"id", "age"},
+					},
+				},
+			},
+		},
+		{
+			name:    "unknown key column",
+			key:     "email",
+			wantErr: "key column not in header: email",
+		},
+		{
+			name:    "duplicate key",
+			a:       [][]string{{"1", "Alice", "23"}, {"1", "Bob", "45"}},
+			key:     "id",
+			wantErr: "a: row 1: duplicate key: 1",
+		},
+		{
+			name:    "inconsistent data length",
+			b:       [][]string{{"1", "Alice"}},
+			key:     "id",
+			wantErr: "b: row 0: inconsistent data length",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := tablemap.Diff(header, tt.a, tt.b, tt.key)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, diff)
+			assert.Equal(t, tt.expected.Empty(), diff.Empty())
+		})
+	}
+}
+
+func TestMarshalDiff(t *testing.T) {
+	type User struct {
+		ID    int     `table:"id"`
+		Name  string  `table:"name"`
+		Email *string `table:"email"`
+		Admin bool    `table:"admin"`
+	}
+	type Other struct {
+		ID int `table:"id"`
+	}
+
+	before := User{ID: 1, Name: "Alice", Admin: false}
+	after := User{ID: 1, Name: "Alice Smith", Email: P("alice@example.com"), Admin: false}
+
+	tests := []struct {
+		name           string
+		before, after  any
+		opts           *tablemap.Options
+		expectedCols   []string
+		expectedBefore []string
+		expectedAfter  []string
+		wantErr        string
+	}{
+		{
+			name:           "changed columns",
+			before:         before,
+			after:          &after,
+			expectedCols:   []string{"name", "email"},
+			expectedBefore: []string{"Alice", "\\N"},
+			expectedAfter:  []string{"Alice Smith", "alice@example.com"},
+		},
+		{
+			name:   "no changes",
+			before: before,
+			after:  before,
+		},
+		{
+			name:           "options",
+			before:         User{Admin: false},
+			after:          User{Admin: true},
+			opts:           tablemap.DefaultOptions().WithBoolFormat("yes", "no"),
+			expectedCols:   []string{"admin"},
+			expectedBefore: []string{"no"},
+			expectedAfter:  []string{"yes"},
+		},
+		{
+			name:    "different types",
+			before:  before,
+			after:   Other{ID: 1},
+			wantErr: "values must have the same type, got tablemap_test.User and tablemap_test.Other",
+		},
+		{
+			name:    "nil",
+			before:  before,
+			after:   (*User)(nil),
+			wantErr: "v must not be nil",
+		},
+		{
+			name:    "not a struct",
+			before:  1,
+			after:   2,
+			wantErr: "v must be a struct or pointer to struct, got int",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, beforeCells, afterCells, err := tablemap.MarshalDiff(tt.before, tt.after, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCols, cols)
+			assert.Equal(t, tt.expectedBefore, beforeCells)
+			assert.Equal(t, tt.expectedAfter, afterCells)
+		})
+	}
+}
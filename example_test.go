@@ -79,3 +79,27 @@ func ExampleMarshal() {
 	// Data: [Bob 25]
 	// Data: [Charlie 27]
 }
+
+func ExampleMarshalVertical() {
+	type User struct {
+		Name  string `table:"name"`
+		Age   int    `table:"age"`
+		Email string `table:"email"`
+	}
+
+	user := User{Name: "Alice", Age: 23, Email: "alice@example.com"}
+
+	pairs, err := tablemap.MarshalVertical(user, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, p := range pairs {
+		fmt.Printf("%-5s: %s\n", p[0], p[1])
+	}
+
+	// Output:
+	// name : Alice
+	// age  : 23
+	// email: alice@example.com
+}
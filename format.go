@@ -0,0 +1,78 @@
+package tablemap
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// Format is a delimited text format read by UnmarshalReader and written by MarshalWriter.
+type Format int
+
+const (
+	// FormatCSV is comma-separated values.
+	FormatCSV Format = iota
+	// FormatTSV is tab-separated values. Quotes are read leniently.
+	FormatTSV
+)
+
+// delimiter returns the field delimiter of the format
+func (f Format) delimiter() (rune, error) {
+	switch f {
+	case FormatCSV:
+		return ',', nil
+	case FormatTSV:
+		return '\t', nil
+	default:
+		return 0, fmt.Errorf("unknown format: %d", f)
+	}
+}
+
+// UnmarshalReader reads a whole table in the given format from r and converts it with UnmarshalWithOptions.
+// The first record is the header. Empty input leaves v unchanged.
+// For finer control over the format, use the csvmap or tsvmap packages.
+func UnmarshalReader(r io.Reader, format Format, v any, opts *Options) error {
+	delim, err := format.delimiter()
+	if err != nil {
+		return err
+	}
+
+	cr := csv.NewReader(r)
+	cr.Comma = delim
+	cr.LazyQuotes = format == FormatTSV
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return err
+	}
+	// Empty input has no header and no data
+	if len(records) == 0 {
+		return nil
+	}
+	return UnmarshalWithOptions(records[0], records[1:], v, opts)
+}
+
+// MarshalWriter converts v with MarshalWithOptions and writes it as a whole table in the given format to w.
+// The header is written even if v is empty.
+func MarshalWriter(w io.Writer, format Format, v any, opts *Options) error {
+	delim, err := format.delimiter()
+	if err != nil {
+		return err
+	}
+
+	header, data, err := MarshalWithOptions(v, opts)
+	if err != nil {
+		return err
+	}
+	return writeTable(w, delim, header, data)
+}
+
+// writeTable writes the header and data as delimited records
+func writeTable(w io.Writer, delim rune, header []string, data [][]string) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = delim
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	return cw.WriteAll(data)
+}
@@ -0,0 +1,118 @@
+package tablemap_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalReader(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		format   tablemap.Format
+		expected []Person
+		wantErr  bool
+	}{
+		{
+			name:     "csv",
+			input:    "name,age\n\"Doe, John\",30\n",
+			format:   tablemap.FormatCSV,
+			expected: []Person{{Name: "Doe, John", Age: 30}},
+		},
+		{
+			name:     "tsv with bare quotes",
+			input:    "name\tage\nJohn \"JD\" Doe\t30\n",
+			format:   tablemap.FormatTSV,
+			expected: []Person{{Name: `John "JD" Doe`, Age: 30}},
+		},
+		{
+			name:     "empty input",
+			input:    "",
+			format:   tablemap.FormatCSV,
+			expected: nil,
+		},
+		{
+			name:    "unknown format",
+			input:   "name,age\n",
+			format:  tablemap.Format(99),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Person
+			err := tablemap.UnmarshalReader(strings.NewReader(tt.input), tt.format, &result, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMarshalWriter(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	input := []Person{{Name: "Doe, John", Age: 30}}
+
+	tests := []struct {
+		name     string
+		input    []Person
+		format   tablemap.Format
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "csv",
+			input:    input,
+			format:   tablemap.FormatCSV,
+			expected: "name,age\n\"Doe, John\",30\n",
+		},
+		{
+			name:     "tsv",
+			input:    input,
+			format:   tablemap.FormatTSV,
+			expected: "name\tage\nDoe, John\t30\n",
+		},
+		{
+			name:     "empty data",
+			input:    []Person{},
+			format:   tablemap.FormatCSV,
+			expected: "name,age\n",
+		},
+		{
+			name:    "unknown format",
+			input:   input,
+			format:  tablemap.Format(99),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tablemap.MarshalWriter(&buf, tt.format, tt.input, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
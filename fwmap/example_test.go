@@ -0,0 +1,72 @@
+package fwmap_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kmio11/tablemap/fwmap"
+)
+
+func ExampleWriteAll() {
+	type Item struct {
+		Code   string `table:"code"`
+		Name   string `table:"name"`
+		Amount int    `table:"amount"`
+	}
+
+	items := []Item{
+		{Code: "A1", Name: "apple", Amount: 120},
+		{Code: "B22", Name: "banana", Amount: 5},
+	}
+
+	layout := &fwmap.Layout{
+		Fields: []fwmap.Field{
+			{Name: "code", Width: 4},
+			{Name: "name", Width: 8},
+			{Name: "amount", Width: 6, Align: fwmap.AlignRight, Pad: '0'},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := fwmap.WriteAll(&buf, layout, items, nil); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	fmt.Print(buf.String())
+	// Output:
+	// A1  apple   000120
+	// B22 banana  000005
+}
+
+func ExampleReadAll() {
+	type Item struct {
+		Code   string `table:"code"`
+		Name   string `table:"name"`
+		Amount int    `table:"amount"`
+	}
+
+	data := "A1  apple   000120\n" +
+		"B22 banana  000005\n"
+
+	layout := &fwmap.Layout{
+		Fields: []fwmap.Field{
+			{Name: "code", Width: 4},
+			{Name: "name", Width: 8},
+			{Name: "amount", Width: 6, Align: fwmap.AlignRight, Pad: '0'},
+		},
+	}
+
+	items, err := fwmap.ReadAll[Item](bytes.NewBufferString(data), layout, nil)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	for _, item := range items {
+		fmt.Printf("%s: %s x %d\n", item.Code, item.Name, item.Amount)
+	}
+	// Output:
+	// A1: apple x 120
+	// B22: banana x 5
+}
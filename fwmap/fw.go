@@ -0,0 +1,164 @@
+package fwmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kmio11/tablemap"
+)
+
+// Alignment of a value within a fixed-width field.
+const (
+	AlignLeft = iota
+	AlignRight
+)
+
+// Field defines a single fixed-width column.
+type Field struct {
+	// Name is the column name, matching the table tag of a struct field.
+	Name string
+	// Width is the number of characters the column occupies.
+	Width int
+	// Align is the alignment of the value within the column, AlignLeft or AlignRight.
+	Align int
+	// Pad is the character used to fill the column.
+	// Default is ' '.
+	Pad rune
+}
+
+// pad returns the padding character of the field, falling back to the default if unset
+func (f Field) pad() rune {
+	if f.Pad == 0 {
+		return ' '
+	}
+	return f.Pad
+}
+
+// format pads or truncates the value to the width of the field
+func (f Field) format(value string, truncate bool) (string, error) {
+	n := utf8.RuneCountInString(value)
+	if n > f.Width {
+		if !truncate {
+			return "", fmt.Errorf("value of column %s exceeds width %d: %q", f.Name, f.Width, value)
+		}
+		return string([]rune(value)[:f.Width]), nil
+	}
+
+	padding := strings.Repeat(string(f.pad()), f.Width-n)
+	if f.Align == AlignRight {
+		return padding + value, nil
+	}
+	return value + padding, nil
+}
+
+// parse removes the padding from a cell according to the alignment of the field.
+// A zero-padded cell consisting only of padding is parsed as "0".
+func (f Field) parse(cell string) string {
+	pad := string(f.pad())
+	var value string
+	if f.Align == AlignRight {
+		value = strings.TrimLeft(cell, pad)
+	} else {
+		value = strings.TrimRight(cell, pad)
+	}
+	if value == "" && cell != "" && f.pad() == '0' {
+		return "0"
+	}
+	return value
+}
+
+// Layout defines the columns of a fixed-width file.
+type Layout struct {
+	// Fields are the columns in the order they appear in a line.
+	Fields []Field
+	// Truncate cuts values that are longer than the column width.
+	// If false, such values cause an error.
+	Truncate bool
+}
+
+// header returns the column names of the layout
+func (l *Layout) header() []string {
+	header := make([]string, len(l.Fields))
+	for i, f := range l.Fields {
+		header[i] = f.Name
+	}
+	return header
+}
+
+// WriteAll writes a slice of struct T as fixed-width lines using the layout.
+func WriteAll[T any](w io.Writer, layout *Layout, data []T, opts *tablemap.Options) error {
+	header, rows, err := tablemap.MarshalWithOptions(data, opts)
+	if err != nil {
+		return err
+	}
+
+	// Find the column index of each field
+	columns := make([]int, len(layout.Fields))
+	for i, f := range layout.Fields {
+		columns[i] = -1
+		for j, h := range header {
+			if h == f.Name {
+				columns[i] = j
+				break
+			}
+		}
+		if columns[i] < 0 {
+			return fmt.Errorf("column %s not found", f.Name)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, row := range rows {
+		for i, f := range layout.Fields {
+			cell, err := f.format(row[columns[i]], layout.Truncate)
+			if err != nil {
+				return err
+			}
+			if _, err := bw.WriteString(cell); err != nil {
+				return err
+			}
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ReadAll reads all fixed-width lines and converts them to a slice of struct T using the layout.
+// Lines shorter than the layout are treated as if the missing columns were empty.
+func ReadAll[T any](r io.Reader, layout *Layout, opts *tablemap.Options) ([]T, error) {
+	var data [][]string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := []rune(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		row := make([]string, len(layout.Fields))
+		offset := 0
+		for i, f := range layout.Fields {
+			start := min(offset, len(line))
+			end := min(offset+f.Width, len(line))
+			row[i] = f.parse(string(line[start:end]))
+			offset += f.Width
+		}
+		data = append(data, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []T
+	if err := tablemap.UnmarshalWithOptions(layout.header(), data, &result, opts); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
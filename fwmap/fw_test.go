@@ -0,0 +1,173 @@
+package fwmap_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/tablemap/fwmap"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestStruct struct {
+	Code   string `table:"code"`
+	Name   string `table:"name"`
+	Amount int    `table:"amount"`
+}
+
+var testLayout = &fwmap.Layout{
+	Fields: []fwmap.Field{
+		{Name: "code", Width: 4},
+		{Name: "name", Width: 8},
+		{Name: "amount", Width: 6, Align: fwmap.AlignRight, Pad: '0'},
+	},
+}
+
+func TestWriteAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		layout   *fwmap.Layout
+		input    []TestStruct
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:   "padding",
+			layout: testLayout,
+			input: []TestStruct{
+				{Code: "A1", Name: "apple", Amount: 120},
+				{Code: "B22", Name: "banana", Amount: 5},
+			},
+			expected: "A1  apple   000120\n" +
+				"B22 banana  000005\n",
+		},
+		{
+			name:   "multibyte characters",
+			layout: testLayout,
+			input: []TestStruct{
+				{Code: "C", Name: "りんご", Amount: 1},
+			},
+			expected: "C   りんご     000001\n",
+		},
+		{
+			name:   "too long value",
+			layout: testLayout,
+			input: []TestStruct{
+				{Code: "A1", Name: "watermelon", Amount: 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "truncate",
+			layout: &fwmap.Layout{
+				Fields:   testLayout.Fields,
+				Truncate: true,
+			},
+			input: []TestStruct{
+				{Code: "A1", Name: "watermelon", Amount: 1},
+			},
+			expected: "A1  watermel000001\n",
+		},
+		{
+			name: "unknown column",
+			layout: &fwmap.Layout{
+				Fields: []fwmap.Field{{Name: "unknown", Width: 4}},
+			},
+			input: []TestStruct{
+				{Code: "A1"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := fwmap.WriteAll(&buf, tt.layout, tt.input, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []TestStruct
+		wantErr  bool
+	}{
+		{
+			name: "padding",
+			input: "A1  apple   000120\n" +
+				"B22 banana  000005\n",
+			expected: []TestStruct{
+				{Code: "A1", Name: "apple", Amount: 120},
+				{Code: "B22", Name: "banana", Amount: 5},
+			},
+		},
+		{
+			name:  "multibyte characters",
+			input: "C   りんご     000001\n",
+			expected: []TestStruct{
+				{Code: "C", Name: "りんご", Amount: 1},
+			},
+		},
+		{
+			name:  "zero padded zero",
+			input: "A1  apple   000000\n",
+			expected: []TestStruct{
+				{Code: "A1", Name: "apple", Amount: 0},
+			},
+		},
+		{
+			name:  "empty line",
+			input: "A1  apple   000120\n\n",
+			expected: []TestStruct{
+				{Code: "A1", Name: "apple", Amount: 120},
+			},
+		},
+		{
+			name:    "short line",
+			input:   "A1  apple\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid number",
+			input:   "A1  apple   00x120\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := fwmap.ReadAll[TestStruct](strings.NewReader(tt.input), testLayout, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := []TestStruct{
+		{Code: "A1", Name: "apple", Amount: 120},
+		{Code: "B22", Name: "banana", Amount: 5},
+		{Code: "C", Name: "", Amount: 0},
+	}
+
+	var buf bytes.Buffer
+	err := fwmap.WriteAll(&buf, testLayout, input, nil)
+	assert.NoError(t, err)
+
+	result, err := fwmap.ReadAll[TestStruct](&buf, testLayout, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+}
@@ -0,0 +1,65 @@
+package tablemap
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// GetValue converts the cell of a single column of a row to T, without unmarshaling the whole row.
+// The column is located by its name in header, and the cell is converted as a field of type T would be.
+func GetValue[T any](row []string, header []string, column string, opts *Options) (T, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	var v T
+	i := slices.Index(header, column)
+	if i < 0 {
+		return v, fmt.Errorf("column not in header: %s", column)
+	}
+	if i >= len(row) {
+		return v, fmt.Errorf("column %s: row has no cell", column)
+	}
+
+	if err := SetValue(reflect.ValueOf(&v).Elem(), row[i], opts); err != nil {
+		return v, fmt.Errorf("column %s: %v", column, err)
+	}
+	return v, nil
+}
+
+// GetString returns the cell of a column of a row.
+func GetString(row []string, header []string, column string) (string, error) {
+	return GetValue[string](row, header, column, nil)
+}
+
+// GetInt converts the cell of a column of a row to an int.
+func GetInt(row []string, header []string, column string) (int, error) {
+	return GetValue[int](row, header, column, nil)
+}
+
+// GetInt64 converts the cell of a column of a row to an int64.
+func GetInt64(row []string, header []string, column string) (int64, error) {
+	return GetValue[int64](row, header, column, nil)
+}
+
+// GetUint converts the cell of a column of a row to a uint.
+func GetUint(row []string, header []string, column string) (uint, error) {
+	return GetValue[uint](row, header, column, nil)
+}
+
+// GetFloat converts the cell of a column of a row to a float64.
+func GetFloat(row []string, header []string, column string) (float64, error) {
+	return GetValue[float64](row, header, column, nil)
+}
+
+// GetBool converts the cell of a column of a row to a bool.
+func GetBool(row []string, header []string, column string) (bool, error) {
+	return GetValue[bool](row, header, column, nil)
+}
+
+// GetTime converts the cell of a column of a row to a time.Time.
+func GetTime(row []string, header []string, column string) (time.Time, error) {
+	return GetValue[time.Time](row, header, column, nil)
+}
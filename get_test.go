@@ -0,0 +1,103 @@
+package tablemap_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	header := []string{"name", "age", "score", "active", "joined", "email"}
+	row := []string{"Alice", "23", "1.5", "true", "2024-01-15T09:30:00Z", "\\N"}
+
+	name, err := tablemap.GetString(row, header, "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", name)
+
+	age, err := tablemap.GetInt(row, header, "age")
+	assert.NoError(t, err)
+	assert.Equal(t, 23, age)
+
+	age64, err := tablemap.GetInt64(row, header, "age")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(23), age64)
+
+	ageUint, err := tablemap.GetUint(row, header, "age")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(23), ageUint)
+
+	score, err := tablemap.GetFloat(row, header, "score")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, score)
+
+	active, err := tablemap.GetBool(row, header, "active")
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	joined, err := tablemap.GetTime(row, header, "joined")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC), joined)
+
+	email, err := tablemap.GetValue[*string](row, header, "email", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, email)
+}
+
+func TestGetValue(t *testing.T) {
+	header := []string{"name", "age"}
+
+	tests := []struct {
+		name     string
+		row      []string
+		column   string
+		opts     *tablemap.Options
+		expected int
+		wantErr  string
+	}{
+		{
+			name:     "options",
+			row:      []string{"Alice", "1,234"},
+			column:   "age",
+			opts:     tablemap.DefaultOptions().WithThousandsSep(","),
+			expected: 1234,
+		},
+		{
+			name:    "unknown column",
+			row:     []string{"Alice", "23"},
+			column:  "email",
+			wantErr: "column not in header: email",
+		},
+		{
+			name:    "short row",
+			row:     []string{"Alice"},
+			column:  "age",
+			wantErr: "column age: row has no cell",
+		},
+		{
+			name:    "invalid value",
+			row:     []string{"Alice", "abc"},
+			column:  "age",
+			wantErr: `column age: strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+		{
+			name:    "nil value",
+			row:     []string{"Alice", "\\N"},
+			column:  "age",
+			wantErr: "column age: cannot set nil to non-pointer field of type: int",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tablemap.GetValue[int](tt.row, header, tt.column, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package htmlmap_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kmio11/tablemap/htmlmap"
+)
+
+func ExampleWriteAllConfig() {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	persons := []Person{
+		{Name: "John Doe", Age: 30},
+		{Name: "Jane Smith", Age: 25},
+	}
+
+	cfg := &htmlmap.Config{TableClass: "report", Caption: "Members"}
+	if err := htmlmap.WriteAllConfig(os.Stdout, persons, nil, cfg); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	// Output:
+	// <table class="report">
+	// <caption>Members</caption>
+	// <thead>
+	// <tr><th>name</th><th>age</th></tr>
+	// </thead>
+	// <tbody>
+	// <tr><td>John Doe</td><td>30</td></tr>
+	// <tr><td>Jane Smith</td><td>25</td></tr>
+	// </tbody>
+	// </table>
+}
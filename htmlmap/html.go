@@ -0,0 +1,72 @@
+package htmlmap
+
+import (
+	"bufio"
+	"html"
+	"io"
+
+	"github.com/kmio11/tablemap"
+)
+
+// Config defines optional attributes of the rendered table.
+type Config struct {
+	// TableClass is the value of the class attribute of the table element.
+	// If empty, no class attribute is written.
+	TableClass string
+	// Caption is the content of the caption element.
+	// If empty, no caption element is written.
+	Caption string
+}
+
+// WriteAll writes a slice of struct T as an HTML table.
+func WriteAll[T any](w io.Writer, data []T, opts *tablemap.Options) error {
+	return WriteAllConfig(w, data, opts, nil)
+}
+
+// WriteAllConfig writes a slice of struct T as an HTML table,
+// applying the table attributes in cfg.
+// The header row is written even if data is empty.
+func WriteAllConfig[T any](w io.Writer, data []T, opts *tablemap.Options, cfg *Config) error {
+	header, rows, err := tablemap.MarshalWithOptions(data, opts)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if cfg.TableClass != "" {
+		bw.WriteString(`<table class="` + html.EscapeString(cfg.TableClass) + `">` + "\n")
+	} else {
+		bw.WriteString("<table>\n")
+	}
+	if cfg.Caption != "" {
+		bw.WriteString("<caption>" + html.EscapeString(cfg.Caption) + "</caption>\n")
+	}
+
+	bw.WriteString("<thead>\n")
+	writeRow(bw, "th", header)
+	bw.WriteString("</thead>\n")
+
+	bw.WriteString("<tbody>\n")
+	for _, row := range rows {
+		writeRow(bw, "td", row)
+	}
+	bw.WriteString("</tbody>\n")
+
+	bw.WriteString("</table>\n")
+
+	// bufio.Writer keeps the first write error, which is reported by Flush
+	return bw.Flush()
+}
+
+// writeRow writes a single tr element with HTML-escaped cells
+func writeRow(bw *bufio.Writer, cellTag string, cells []string) {
+	bw.WriteString("<tr>")
+	for _, cell := range cells {
+		bw.WriteString("<" + cellTag + ">" + html.EscapeString(cell) + "</" + cellTag + ">")
+	}
+	bw.WriteString("</tr>\n")
+}
@@ -0,0 +1,92 @@
+package htmlmap_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kmio11/tablemap/htmlmap"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestStruct struct {
+	Name string  `table:"name"`
+	Note *string `table:"note"`
+}
+
+func P[T any](t T) *T {
+	return &t
+}
+
+func TestWriteAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []TestStruct
+		cfg      *htmlmap.Config
+		expected string
+	}{
+		{
+			name: "rows",
+			input: []TestStruct{
+				{Name: "John", Note: P("a & b")},
+				{Name: "<Jane>", Note: nil},
+			},
+			expected: "<table>\n" +
+				"<thead>\n" +
+				"<tr><th>name</th><th>note</th></tr>\n" +
+				"</thead>\n" +
+				"<tbody>\n" +
+				"<tr><td>John</td><td>a &amp; b</td></tr>\n" +
+				"<tr><td>&lt;Jane&gt;</td><td>\\N</td></tr>\n" +
+				"</tbody>\n" +
+				"</table>\n",
+		},
+		{
+			name:  "zero rows",
+			input: []TestStruct{},
+			expected: "<table>\n" +
+				"<thead>\n" +
+				"<tr><th>name</th><th>note</th></tr>\n" +
+				"</thead>\n" +
+				"<tbody>\n" +
+				"</tbody>\n" +
+				"</table>\n",
+		},
+		{
+			name: "class and caption",
+			input: []TestStruct{
+				{Name: "John", Note: P("x")},
+			},
+			cfg: &htmlmap.Config{TableClass: "report \"wide\"", Caption: "Users & Notes"},
+			expected: "<table class=\"report &#34;wide&#34;\">\n" +
+				"<caption>Users &amp; Notes</caption>\n" +
+				"<thead>\n" +
+				"<tr><th>name</th><th>note</th></tr>\n" +
+				"</thead>\n" +
+				"<tbody>\n" +
+				"<tr><td>John</td><td>x</td></tr>\n" +
+				"</tbody>\n" +
+				"</table>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := htmlmap.WriteAllConfig(&buf, tt.input, nil, tt.cfg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriteAll_writeError(t *testing.T) {
+	err := htmlmap.WriteAll(errWriter{}, []TestStruct{{Name: "John"}}, nil)
+	assert.EqualError(t, err, "write failed")
+}
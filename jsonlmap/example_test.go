@@ -0,0 +1,51 @@
+package jsonlmap_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kmio11/tablemap/jsonlmap"
+)
+
+func ExampleWriteAll() {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	persons := []Person{
+		{Name: "John Doe", Age: 30},
+		{Name: "Jane Smith", Age: 25},
+	}
+
+	if err := jsonlmap.WriteAll(os.Stdout, persons, nil); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	// Output:
+	// {"name":"John Doe","age":"30"}
+	// {"name":"Jane Smith","age":"25"}
+}
+
+func ExampleReadAll() {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	input := `{"name":"John Doe","age":30}
+{"name":"Jane Smith","age":"25"}
+`
+	persons, err := jsonlmap.ReadAll[Person](strings.NewReader(input), nil)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, p := range persons {
+		fmt.Printf("%+v\n", p)
+	}
+	// Output:
+	// {Name:John Doe Age:30}
+	// {Name:Jane Smith Age:25}
+}
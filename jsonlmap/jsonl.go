@@ -0,0 +1,137 @@
+package jsonlmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+
+	"github.com/kmio11/tablemap"
+)
+
+// WriteAll writes a slice of struct T as newline-delimited JSON objects.
+// Each object maps the column names to the formatted cells, in column order.
+// Records that are all zero are skipped if Options.OmitZeroRows is set.
+func WriteAll[T any](w io.Writer, data []T, opts *tablemap.Options) error {
+	handler, err := tablemap.NewRowHandler[T](nil, opts)
+	if err != nil {
+		return err
+	}
+	header := handler.Header()
+
+	bw := bufio.NewWriter(w)
+	for i := range data {
+		if handler.OmitRow(&data[i]) {
+			continue
+		}
+		row, err := handler.MarshalRow(&data[i])
+		if err != nil {
+			return err
+		}
+
+		line, err := encodeObject(header, row)
+		if err != nil {
+			return err
+		}
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// encodeObject encodes a row as a JSON object followed by a newline.
+// Unlike json.Marshal of a map, the keys keep the order of the header.
+func encodeObject(header, row []string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	buf.WriteByte('{')
+	for i, name := range header {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(name); err != nil {
+			return nil, err
+		}
+		// Encode appends a newline, which is replaced by the separator
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		if err := enc.Encode(row[i]); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// ReadAll reads newline-delimited JSON objects and converts them to a slice of struct T.
+// The keys of each object are used as the header of that object, so objects may omit keys
+// or carry keys that do not map to any field.
+// String values are used as cells as they are, null is read as the nil value of opts,
+// and other values are used as their JSON text.
+// A failing object is reported as a *tablemap.UnmarshalError with the index of the object.
+func ReadAll[T any](r io.Reader, opts *tablemap.Options) ([]T, error) {
+	if opts == nil {
+		opts = tablemap.DefaultOptions()
+	}
+
+	var result []T
+	dec := json.NewDecoder(r)
+	for i := 0; ; i++ {
+		var object map[string]json.RawMessage
+		err := dec.Decode(&object)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		header, row, err := decodeObject(object, opts)
+		if err != nil {
+			return nil, &tablemap.UnmarshalError{Row: i, Err: err}
+		}
+
+		handler, err := tablemap.NewRowHandler[T](header, opts)
+		if err != nil {
+			return nil, &tablemap.UnmarshalError{Row: i, Err: err}
+		}
+		record, err := handler.UnmarshalRow(row)
+		if err != nil {
+			return nil, &tablemap.UnmarshalError{Row: i, Err: err}
+		}
+		result = append(result, *record)
+	}
+
+	return result, nil
+}
+
+// decodeObject converts a decoded JSON object into a header sorted by key and its row of cells
+func decodeObject(object map[string]json.RawMessage, opts *tablemap.Options) ([]string, []string, error) {
+	header := make([]string, 0, len(object))
+	for key := range object {
+		header = append(header, key)
+	}
+	slices.Sort(header)
+
+	row := make([]string, len(header))
+	for i, key := range header {
+		raw := bytes.TrimSpace(object[key])
+		switch {
+		case bytes.Equal(raw, []byte("null")):
+			row[i] = opts.NilValue
+		case len(raw) > 0 && raw[0] == '"':
+			if err := json.Unmarshal(raw, &row[i]); err != nil {
+				return nil, nil, err
+			}
+		default:
+			row[i] = string(raw)
+		}
+	}
+	return header, row, nil
+}
@@ -0,0 +1,164 @@
+package jsonlmap_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/kmio11/tablemap/jsonlmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestStruct struct {
+	Name  string  `table:"name"`
+	Age   int     `table:"age"`
+	Note  *string `table:"note"`
+	Extra string
+}
+
+func P[T any](t T) *T {
+	return &t
+}
+
+func TestWriteAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []TestStruct
+		opts     *tablemap.Options
+		expected string
+	}{
+		{
+			name: "rows",
+			input: []TestStruct{
+				{Name: "John", Age: 30, Note: P("a & <b>")},
+				{Name: "Jane \"J\"", Age: 25, Note: nil},
+			},
+			expected: `{"name":"John","age":"30","note":"a & <b>"}` + "\n" +
+				`{"name":"Jane \"J\"","age":"25","note":"\\N"}` + "\n",
+		},
+		{
+			name: "output aliases",
+			input: []TestStruct{
+				{Name: "John", Age: 30},
+			},
+			opts: &tablemap.Options{
+				NilValue:      "NULL",
+				OutputAliases: map[string]string{"name": "Full Name"},
+			},
+			expected: `{"Full Name":"John","age":"30","note":"NULL"}` + "\n",
+		},
+		{
+			name: "omit zero rows",
+			input: []TestStruct{
+				{Name: "John", Age: 30},
+				{Extra: "not a column"},
+			},
+			opts:     tablemap.DefaultOptions().WithOmitZeroRows(true),
+			expected: `{"name":"John","age":"30","note":"\\N"}` + "\n",
+		},
+		{
+			name:     "empty",
+			input:    []TestStruct{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := jsonlmap.WriteAll(&buf, tt.input, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		opts      *tablemap.Options
+		expected  []TestStruct
+		wantError bool
+	}{
+		{
+			name: "strings",
+			input: `{"name":"John","age":"30","note":"hello"}` + "\n" +
+				`{"name":"Jane","age":"25","note":"\\N"}` + "\n",
+			expected: []TestStruct{
+				{Name: "John", Age: 30, Note: P("hello")},
+				{Name: "Jane", Age: 25, Note: nil},
+			},
+		},
+		{
+			name:  "JSON values",
+			input: `{"name":"John","age":30,"note":null}`,
+			expected: []TestStruct{
+				{Name: "John", Age: 30, Note: nil},
+			},
+		},
+		{
+			name: "missing and unknown keys",
+			input: `{"age":"30","unknown":"x"}` + "\n" +
+				"\n" +
+				`{"name":"Jane"}` + "\n",
+			expected: []TestStruct{
+				{Age: 30},
+				{Name: "Jane"},
+			},
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:      "invalid cell",
+			input:     `{"name":"John","age":"thirty"}`,
+			wantError: true,
+		},
+		{
+			name:      "invalid JSON",
+			input:     `{"name":`,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonlmap.ReadAll[TestStruct](strings.NewReader(tt.input), tt.opts)
+			if tt.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestReadAll_ErrorObject(t *testing.T) {
+	input := `{"name":"John","age":"30"}` + "\n" + `{"name":"Jane","age":"x"}` + "\n"
+	_, err := jsonlmap.ReadAll[TestStruct](strings.NewReader(input), nil)
+
+	var unmarshalErr *tablemap.UnmarshalError
+	require.True(t, errors.As(err, &unmarshalErr))
+	assert.Equal(t, 1, unmarshalErr.Row)
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := []TestStruct{
+		{Name: "John", Age: 30, Note: P("line1\nline2")},
+		{Name: "Jane", Age: 25},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, jsonlmap.WriteAll(&buf, input, nil))
+
+	result, err := jsonlmap.ReadAll[TestStruct](&buf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, input, result)
+}
@@ -0,0 +1,47 @@
+package tablemap
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Merge converts two slices of structs of equal length into a single table,
+// with the columns of a followed by the columns of b.
+// The i-th row holds the cells of a[i] and b[i], which is useful to join
+// two views of the same entities without defining a combined struct.
+// It is an error for a and b to share a column name or to differ in length.
+// opts.Header is ignored, since a single header cannot apply to both types.
+// opts.OmitZeroRows is ignored too, since skipping rows on either side would pair
+// the remaining rows with the wrong partners.
+func Merge(a, b any, opts *Options) ([]string, [][]string, error) {
+	if opts != nil && (opts.Header != nil || opts.OmitZeroRows) {
+		opts = opts.Clone()
+		opts.Header = nil
+		opts.OmitZeroRows = false
+	}
+
+	headerA, dataA, err := MarshalWithOptions(a, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	headerB, dataB, err := MarshalWithOptions(b, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, h := range headerB {
+		if slices.Contains(headerA, h) {
+			return nil, nil, fmt.Errorf("column %s is in both a and b", h)
+		}
+	}
+	if len(dataA) != len(dataB) {
+		return nil, nil, fmt.Errorf("a has %d rows but b has %d", len(dataA), len(dataB))
+	}
+
+	header := slices.Concat(headerA, headerB)
+	data := make([][]string, len(dataA))
+	for i := range dataA {
+		data[i] = slices.Concat(dataA[i], dataB[i])
+	}
+	return header, data, nil
+}
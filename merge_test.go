@@ -0,0 +1,98 @@
+package tablemap_test
+
+import (
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	type Person struct {
+		ID   int    `table:"id"`
+		Name string `table:"name"`
+	}
+	type Stats struct {
+		Score int     `table:"score"`
+		Rate  float64 `table:"rate"`
+	}
+	type Other struct {
+		Name string `table:"name"`
+	}
+
+	people := []Person{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}}
+
+	tests := []struct {
+		name           string
+		a, b           any
+		opts           *tablemap.Options
+		expectedHeader []string
+		expectedData   [][]string
+		wantErr        string
+	}{
+		{
+			name:           "columns of both",
+			a:              people,
+			b:              []Stats{{Score: 10, Rate: 0.5}, {Score: 20, Rate: 1.5}},
+			expectedHeader: []string{"id", "name", "score", "rate"},
+			expectedData: [][]string{
+				{"1", "Alice", "10", "0.5"},
+				{"2", "Bob", "20", "1.5"},
+			},
+		},
+		{
+			name:           "empty slices",
+			a:              []Person{},
+			b:              []Stats{},
+			expectedHeader: []string{"id", "name", "score", "rate"},
+			expectedData:   [][]string{},
+		},
+		{
+			name:           "header option is ignored",
+			a:              people[:1],
+			b:              []Stats{{Score: 10}},
+			opts:           tablemap.DefaultOptions().WithHeader([]string{"id"}),
+			expectedHeader: []string{"id", "name", "score", "rate"},
+			expectedData:   [][]string{{"1", "Alice", "10", "0"}},
+		},
+		{
+			name:           "omit zero rows option is ignored",
+			a:              []Stats{{}, {Score: 20}},
+			b:              []Other{{Name: "x"}, {}},
+			opts:           tablemap.DefaultOptions().WithOmitZeroRows(true),
+			expectedHeader: []string{"score", "rate", "name"},
+			expectedData:   [][]string{{"0", "0", "x"}, {"20", "0", ""}},
+		},
+		{
+			name:    "column collision",
+			a:       people,
+			b:       []Other{{Name: "x"}, {Name: "y"}},
+			wantErr: "column name is in both a and b",
+		},
+		{
+			name:    "length mismatch",
+			a:       people,
+			b:       []Stats{{Score: 10}},
+			wantErr: "a has 2 rows but b has 1",
+		},
+		{
+			name:    "not a slice",
+			a:       people,
+			b:       Stats{},
+			wantErr: "v must be a slice or array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, data, err := tablemap.Merge(tt.a, tt.b, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedHeader, header)
+			assert.Equal(t, tt.expectedData, data)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package tablemap
+
+import "reflect"
+
+// Null represents a value that may be null, as an alternative to a pointer field.
+// When marshaled, an invalid Null is written as Options.NilValue.
+// When unmarshaled, a nil cell makes it invalid, just as it would set a pointer field to nil.
+type Null[T any] struct {
+	Value T
+	Valid bool // Valid is true if Value is not null
+}
+
+// nullCell is implemented by Null so that it is converted with the options in use,
+// which CellMarshaler and CellUnmarshaler do not have access to.
+type nullCell interface {
+	marshalCellOptions(opts *Options) (string, error)
+	unmarshalCellOptions(value string, opts *Options) error
+}
+
+// MarshalCell implements CellMarshaler using the default options.
+func (n *Null[T]) MarshalCell() (string, error) {
+	return n.marshalCellOptions(DefaultOptions())
+}
+
+// UnmarshalCell implements CellUnmarshaler using the default options.
+func (n *Null[T]) UnmarshalCell(value string) error {
+	return n.unmarshalCellOptions(value, DefaultOptions())
+}
+
+func (n *Null[T]) marshalCellOptions(opts *Options) (string, error) {
+	opts = opts.forType(reflect.TypeFor[T]())
+	if !n.Valid {
+		return opts.NilValue, nil
+	}
+	return formatField(reflect.ValueOf(&n.Value).Elem(), opts)
+}
+
+func (n *Null[T]) unmarshalCellOptions(value string, opts *Options) error {
+	opts = opts.forType(reflect.TypeFor[T]())
+	*n = Null[T]{}
+	if opts.isNil(value) || (value == "" && !opts.EmptyStringNotNil) {
+		return nil
+	}
+	if err := setField(reflect.ValueOf(&n.Value).Elem(), value, opts); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
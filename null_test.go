@@ -0,0 +1,100 @@
+package tablemap_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNull(t *testing.T) {
+	type Record struct {
+		Int  tablemap.Null[int]       `table:"int"`
+		Str  tablemap.Null[string]    `table:"str"`
+		Time tablemap.Null[time.Time] `table:"time"`
+	}
+
+	at := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	input := []Record{
+		{
+			Int:  tablemap.Null[int]{Value: 42, Valid: true},
+			Str:  tablemap.Null[string]{Value: "hello", Valid: true},
+			Time: tablemap.Null[time.Time]{Value: at, Valid: true},
+		},
+		{},
+	}
+
+	tests := []struct {
+		name     string
+		opts     *tablemap.Options
+		expected [][]string
+	}{
+		{
+			name: "default nil value",
+			opts: nil,
+			expected: [][]string{
+				{"42", "hello", "2024-01-01T09:00:00Z"},
+				{"\\N", "\\N", "\\N"},
+			},
+		},
+		{
+			name: "configured nil value",
+			opts: tablemap.DefaultOptions().WithNilValue("NULL"),
+			expected: [][]string{
+				{"42", "hello", "2024-01-01T09:00:00Z"},
+				{"NULL", "NULL", "NULL"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, data, err := tablemap.MarshalWithOptions(input, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+
+			// Round trip
+			var result []Record
+			err = tablemap.UnmarshalWithOptions(header, data, &result, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, input, result)
+		})
+	}
+
+	t.Run("empty cell", func(t *testing.T) {
+		var result []Record
+		err := tablemap.Unmarshal([]string{"str"}, [][]string{{""}}, &result)
+		assert.NoError(t, err)
+		assert.False(t, result[0].Str.Valid)
+
+		opts := tablemap.DefaultOptions().WithEmptyStringNotNil(true)
+		err = tablemap.UnmarshalWithOptions([]string{"str"}, [][]string{{""}}, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, tablemap.Null[string]{Value: "", Valid: true}, result[1].Str)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var result []Record
+		err := tablemap.Unmarshal([]string{"int"}, [][]string{{"abc"}}, &result)
+		assert.Error(t, err)
+	})
+}
+
+func TestNull_CellMarshaler(t *testing.T) {
+	n := tablemap.Null[int]{Value: 1, Valid: true}
+	cell, err := n.MarshalCell()
+	assert.NoError(t, err)
+	assert.Equal(t, "1", cell)
+
+	var invalid tablemap.Null[int]
+	cell, err = invalid.MarshalCell()
+	assert.NoError(t, err)
+	assert.Equal(t, "\\N", cell)
+
+	var parsed tablemap.Null[int]
+	assert.NoError(t, parsed.UnmarshalCell("7"))
+	assert.Equal(t, tablemap.Null[int]{Value: 7, Valid: true}, parsed)
+	assert.NoError(t, parsed.UnmarshalCell("\\N"))
+	assert.Equal(t, tablemap.Null[int]{}, parsed)
+}
@@ -0,0 +1,883 @@
+package tablemap
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IntBaseAuto is the value of Options.IntBase that detects the base of integers from their prefix.
+const IntBaseAuto = -1
+
+// Options defines configuration options for marshaling and unmarshaling.
+type Options struct {
+	// NilValue is the string representation of nil values.
+	// Default is "\N".
+	NilValue string
+
+	// NilValuesPerKind maps the kinds of fields to their own NilValue, for data that represents
+	// null differently depending on the type, e.g. {reflect.String: ""} with "\N" for other kinds.
+	// The kind of a pointer field is the kind of the type it points to, and the kind of a Null[T] field is that of T.
+	// Kinds without an entry use NilValue.
+	NilValuesPerKind map[reflect.Kind]string
+
+	// IsNil, if set, reports whether a cell represents nil when unmarshaling,
+	// overriding the comparison with NilValue. This allows accepting several
+	// spellings of nil, such as "NULL", "null" and "".
+	// NilValue is still used when marshaling.
+	IsNil func(value string) bool
+
+	// EscapeNilCollision escapes non-nil values that would otherwise be read back as nil.
+	// When marshaling, a cell that equals NilValue (or matches IsNil), optionally preceded
+	// by NilEscape prefixes, is prefixed with one more NilEscape.
+	// When unmarshaling, one NilEscape prefix is removed from such cells.
+	// This guarantees that a non-nil string equal to NilValue round-trips as a non-nil value.
+	EscapeNilCollision bool
+
+	// NilEscape is the prefix used by EscapeNilCollision.
+	// Default is a single backslash.
+	NilEscape string
+
+	// EmptyStringNotNil stops empty cells from unmarshaling to nil pointers.
+	// By default, an empty cell sets a pointer field to nil just like NilValue.
+	// When set, an empty cell is converted like any other value,
+	// so a *string field can hold a pointer to an empty string.
+	EmptyStringNotNil bool
+
+	// BoolFormat is the string representation of bool values.
+	// Default is {True: "true", False: "false"}.
+	// When unmarshaling, the inputs accepted by strconv.ParseBool are also accepted.
+	BoolFormat BoolFormat
+
+	// StrictTypes rejects cells that only convert to a field through lenient parsing.
+	// Bool fields accept only the BoolFormat strings and the BoolTrue and BoolFalse tokens,
+	// rather than every input of strconv.ParseBool such as "1" and "T".
+	// Integer fields reject an explicit plus sign and leading zeros, such as "+1" and "007",
+	// since the leading zeros of codes are lost. Values with a fractional part, such as "1.0",
+	// are rejected regardless of the option.
+	StrictTypes bool
+
+	// BoolTrue and BoolFalse are additional tokens accepted as true and false when unmarshaling,
+	// such as "yes" and "no". They are matched case-insensitively before strconv.ParseBool.
+	BoolTrue  []string
+	BoolFalse []string
+
+	// TagName is the struct tag key read for column names and tag options,
+	// such as "csv" for structs tagged for another library.
+	// Default is "table".
+	TagName string
+
+	// NestedSeparator is the separator between the tag of a nested struct field
+	// and the tags of its fields when they are flattened into columns.
+	// Default is ".".
+	NestedSeparator string
+
+	// MapEntrySeparator is the separator between the entries of a map field
+	// marshaled into a single cell, such as "a=1;b=2".
+	// Default is ";".
+	MapEntrySeparator string
+
+	// MapKVSeparator is the separator between the key and the value of a map entry.
+	// Default is "=".
+	MapKVSeparator string
+
+	// EscapeMapSeparators escapes the separators and backslashes inside the keys and values
+	// of map fields with a backslash when marshaling, and unescapes them when unmarshaling,
+	// so that every map round-trips. Keys and values that would be read back as nil
+	// are escaped as with EscapeNilCollision. By default, separators are not escaped.
+	EscapeMapSeparators bool
+
+	// TrimSpace trims leading and trailing white space from cells before conversion when unmarshaling.
+	// This also applies to string fields.
+	// The comparison with NilValue is done before trimming.
+	TrimSpace bool
+
+	// FloatFormat is the format used for float and big.Float values, as accepted by strconv.FormatFloat
+	// ('f', 'e', 'g', etc.).
+	// Default is 'f'.
+	FloatFormat byte
+
+	// FloatPrecision is the precision used for float values, as accepted by strconv.FormatFloat.
+	// -1 uses the smallest number of digits necessary to represent the value exactly.
+	// It is only used when FloatFormat is set.
+	// Default is -1.
+	FloatPrecision int
+
+	// IntBase is the base of integer values, from 2 to 36.
+	// Default is 10. Set it to IntBaseAuto to accept prefixed literals such as "0xFF",
+	// "0o755" and "0b101" when unmarshaling, as strconv.ParseInt does with base 0.
+	// Integers are marshaled in IntBase, or in base 10 with IntBaseAuto.
+	// Other values are reported as an error when converting integers.
+	IntBase int
+
+	// ThousandsSep is a digit grouping separator, such as ",", for integer and float values.
+	// When set, it is removed from the cells of numeric fields before parsing,
+	// and inserted every three digits of the integer part when marshaling.
+	// Integers in a base other than 10 are marshaled without separators.
+	ThousandsSep string
+
+	// TimeLayout is the layout used for time.Time values, as accepted by time.Parse.
+	// Default is time.RFC3339, with fractional seconds written when present.
+	TimeLayout string
+
+	// Location is the location used to parse time.Time values without zone information,
+	// and the location time.Time values are converted to when marshaling.
+	// If nil, such values are parsed in UTC and written in their own location.
+	Location *time.Location
+
+	// Header, if set, is the list of columns to marshal, in order.
+	// It may list any subset of the struct's tags (or their OutputAliases),
+	// and it is an error for it to contain a column that does not map to a field.
+	// When unmarshaling, it is used only if the header passed to UnmarshalWithOptions is nil,
+	// so the header found in the data always takes precedence.
+	Header []string
+
+	// HeaderAliases maps incoming header names to tags when unmarshaling.
+	// For example, {"full_name": "name"} maps the "full_name" column to the field tagged "name".
+	HeaderAliases map[string]string
+
+	// OutputAliases maps tags to outgoing header names when marshaling.
+	// Outgoing header names are also accepted when unmarshaling, so marshaled data can be read back.
+	OutputAliases map[string]string
+
+	// HeaderTransform, if set, normalizes incoming header names before they are matched to tags
+	// when unmarshaling, e.g. by lowercasing them and replacing spaces with underscores.
+	// HeaderAliases and OutputAliases are looked up with the transformed names.
+	// Headers that map to the same tag are an error.
+	HeaderTransform func(string) string
+
+	// HeaderCaseFold matches incoming header names to tags case-insensitively when unmarshaling,
+	// using Unicode case folding as strings.EqualFold does.
+	// A header matching a tag exactly is never folded. A header matching several tags
+	// after folding, and headers that map to the same tag, are an error.
+	HeaderCaseFold bool
+
+	// FuzzyHeaderMatch matches incoming header names that match no tag otherwise to the tag
+	// that is equal after removing everything but letters and digits and lowercasing,
+	// so that "E-Mail" matches "email". It is applied after HeaderCaseFold when unmarshaling.
+	// A header matching several tags this way, and headers that map to the same tag, are an error.
+	FuzzyHeaderMatch bool
+
+	// ErrorOnDuplicateHeader makes a header with the same column more than once an error.
+	// Columns are compared after applying HeaderAliases, so an alias and its tag are duplicates.
+	// By default, the last of the duplicate columns wins.
+	ErrorOnDuplicateHeader bool
+
+	// CollectErrors makes UnmarshalWithOptions keep going past rows that fail to unmarshal.
+	// Bad rows are skipped, and the errors of all of them are returned joined together
+	// along with the successfully parsed rows.
+	CollectErrors bool
+
+	// MaxErrors, if positive, bounds the number of row errors collected with CollectErrors.
+	// Unmarshaling stops once that many rows have failed, returning the rows parsed so far
+	// along with the collected errors.
+	MaxErrors int
+
+	// FieldErrorHandler, if set, is called when a cell fails to convert while unmarshaling,
+	// with the index of the row, the header name of the column, the cell and the error.
+	// If it returns nil, the field is left at its zero value and unmarshaling continues;
+	// otherwise the returned error fails the row.
+	// For a RowHandler, the row index counts the rows it unmarshaled since it was created or Reset.
+	FieldErrorHandler func(row int, column, value string, err error) error
+
+	// DefaultValues maps tags to default cell values used when unmarshaling.
+	// When a cell is empty or equals NilValue, the default value is converted instead,
+	// so pointer fields with a default value become non-nil.
+	// Cells missing from ragged rows are treated as empty.
+	// These take precedence over defaults given with the default tag option.
+	DefaultValues map[string]string
+
+	// ColumnTransforms maps tags to functions applied to a cell when unmarshaling,
+	// before the cell is converted, e.g. to strip a currency symbol.
+	ColumnTransforms map[string]func(string) string
+
+	// OutputTransforms maps tags to functions applied to a formatted cell when marshaling.
+	OutputTransforms map[string]func(string) string
+
+	// EnumMaps maps integer-kinded named types to the names of their values.
+	// For example, {reflect.TypeOf(Status(0)): {"Active": 0, "Inactive": 1}}
+	// marshals Status(1) as "Inactive" and unmarshals "Active" as Status(0).
+	// Values without a name are marshaled as numbers, and unmarshaling accepts such numbers.
+	// Unmarshaling any other name that is not in the mapping is an error.
+	// A mapping takes precedence over CellMarshaler and encoding.TextMarshaler implementations.
+	EnumMaps map[reflect.Type]map[string]int64
+
+	// AllowRaggedRows allows rows whose length differs from the header when unmarshaling.
+	// Fields of missing trailing cells are left unchanged unless they have a default value or are required,
+	// in which case the cells are treated as empty. Extra cells are ignored.
+	// By default, such rows cause an error.
+	AllowRaggedRows bool
+
+	// OmitZeroRows skips values whose mapped fields are all zero when marshaling a slice,
+	// such as with MarshalWithOptions, Table.AddRow or the writers of the subpackages. Only the fields of the marshaled columns are considered.
+	// A record that is legitimately all zero is skipped too, so its row is lost.
+	// RowHandler.MarshalRow does not skip values; use RowHandler.OmitRow to check them.
+	OmitZeroRows bool
+}
+
+// BoolFormat defines the string representation of true and false.
+type BoolFormat struct {
+	True  string
+	False string
+}
+
+// DefaultOptions returns the default options.
+func DefaultOptions() *Options {
+	return &Options{
+		NilValue: "\\N",
+		BoolFormat: BoolFormat{
+			True:  "true",
+			False: "false",
+		},
+		TagName:           tagTable,
+		NestedSeparator:   ".",
+		MapEntrySeparator: ";",
+		MapKVSeparator:    "=",
+		FloatFormat:       'f',
+		FloatPrecision:    -1,
+		IntBase:           10,
+	}
+}
+
+// Clone returns a shallow copy of the options.
+// Maps are shared with the original, so they must not be modified through the copy.
+// Cloning nil options returns the default options.
+func (o *Options) Clone() *Options {
+	if o == nil {
+		return DefaultOptions()
+	}
+	c := *o
+	return &c
+}
+
+// WithNilValue returns a copy of the options with NilValue set.
+func (o *Options) WithNilValue(nilValue string) *Options {
+	c := o.Clone()
+	c.NilValue = nilValue
+	return c
+}
+
+// WithNilValuesPerKind returns a copy of the options with NilValuesPerKind set.
+func (o *Options) WithNilValuesPerKind(values map[reflect.Kind]string) *Options {
+	c := o.Clone()
+	c.NilValuesPerKind = values
+	return c
+}
+
+// WithIsNil returns a copy of the options with IsNil set.
+func (o *Options) WithIsNil(isNil func(value string) bool) *Options {
+	c := o.Clone()
+	c.IsNil = isNil
+	return c
+}
+
+// WithEscapeNilCollision returns a copy of the options with EscapeNilCollision set.
+func (o *Options) WithEscapeNilCollision(escape bool) *Options {
+	c := o.Clone()
+	c.EscapeNilCollision = escape
+	return c
+}
+
+// WithNilEscape returns a copy of the options with NilEscape set.
+func (o *Options) WithNilEscape(escape string) *Options {
+	c := o.Clone()
+	c.NilEscape = escape
+	return c
+}
+
+// WithEmptyStringNotNil returns a copy of the options with EmptyStringNotNil set.
+func (o *Options) WithEmptyStringNotNil(notNil bool) *Options {
+	c := o.Clone()
+	c.EmptyStringNotNil = notNil
+	return c
+}
+
+// WithBoolFormat returns a copy of the options with BoolFormat set.
+func (o *Options) WithBoolFormat(trueValue, falseValue string) *Options {
+	c := o.Clone()
+	c.BoolFormat = BoolFormat{True: trueValue, False: falseValue}
+	return c
+}
+
+// WithBoolTokens returns a copy of the options with BoolTrue and BoolFalse set.
+func (o *Options) WithBoolTokens(trueTokens, falseTokens []string) *Options {
+	c := o.Clone()
+	c.BoolTrue = trueTokens
+	c.BoolFalse = falseTokens
+	return c
+}
+
+// WithStrictTypes returns a copy of the options with StrictTypes set.
+func (o *Options) WithStrictTypes(strict bool) *Options {
+	c := o.Clone()
+	c.StrictTypes = strict
+	return c
+}
+
+// WithTagName returns a copy of the options with TagName set.
+func (o *Options) WithTagName(name string) *Options {
+	c := o.Clone()
+	c.TagName = name
+	return c
+}
+
+// WithNestedSeparator returns a copy of the options with NestedSeparator set.
+func (o *Options) WithNestedSeparator(sep string) *Options {
+	c := o.Clone()
+	c.NestedSeparator = sep
+	return c
+}
+
+// WithMapSeparators returns a copy of the options with MapEntrySeparator and MapKVSeparator set.
+func (o *Options) WithMapSeparators(entrySep, kvSep string) *Options {
+	c := o.Clone()
+	c.MapEntrySeparator = entrySep
+	c.MapKVSeparator = kvSep
+	return c
+}
+
+// WithEscapeMapSeparators returns a copy of the options with EscapeMapSeparators set.
+func (o *Options) WithEscapeMapSeparators(escape bool) *Options {
+	c := o.Clone()
+	c.EscapeMapSeparators = escape
+	return c
+}
+
+// WithTrimSpace returns a copy of the options with TrimSpace set.
+func (o *Options) WithTrimSpace(trim bool) *Options {
+	c := o.Clone()
+	c.TrimSpace = trim
+	return c
+}
+
+// WithFloatFormat returns a copy of the options with FloatFormat and FloatPrecision set.
+func (o *Options) WithFloatFormat(format byte, precision int) *Options {
+	c := o.Clone()
+	c.FloatFormat = format
+	c.FloatPrecision = precision
+	return c
+}
+
+// WithIntBase returns a copy of the options with IntBase set.
+func (o *Options) WithIntBase(base int) *Options {
+	c := o.Clone()
+	c.IntBase = base
+	return c
+}
+
+// WithThousandsSep returns a copy of the options with ThousandsSep set.
+func (o *Options) WithThousandsSep(sep string) *Options {
+	c := o.Clone()
+	c.ThousandsSep = sep
+	return c
+}
+
+// WithTimeLayout returns a copy of the options with TimeLayout set.
+func (o *Options) WithTimeLayout(layout string) *Options {
+	c := o.Clone()
+	c.TimeLayout = layout
+	return c
+}
+
+// WithLocation returns a copy of the options with Location set.
+func (o *Options) WithLocation(loc *time.Location) *Options {
+	c := o.Clone()
+	c.Location = loc
+	return c
+}
+
+// WithHeader returns a copy of the options with Header set.
+func (o *Options) WithHeader(header []string) *Options {
+	c := o.Clone()
+	c.Header = header
+	return c
+}
+
+// WithHeaderAliases returns a copy of the options with HeaderAliases set.
+func (o *Options) WithHeaderAliases(aliases map[string]string) *Options {
+	c := o.Clone()
+	c.HeaderAliases = aliases
+	return c
+}
+
+// WithOutputAliases returns a copy of the options with OutputAliases set.
+func (o *Options) WithOutputAliases(aliases map[string]string) *Options {
+	c := o.Clone()
+	c.OutputAliases = aliases
+	return c
+}
+
+// WithHeaderTransform returns a copy of the options with HeaderTransform set.
+func (o *Options) WithHeaderTransform(transform func(string) string) *Options {
+	c := o.Clone()
+	c.HeaderTransform = transform
+	return c
+}
+
+// WithHeaderCaseFold returns a copy of the options with HeaderCaseFold set.
+func (o *Options) WithHeaderCaseFold(fold bool) *Options {
+	c := o.Clone()
+	c.HeaderCaseFold = fold
+	return c
+}
+
+// WithFuzzyHeaderMatch returns a copy of the options with FuzzyHeaderMatch set.
+func (o *Options) WithFuzzyHeaderMatch(fuzzy bool) *Options {
+	c := o.Clone()
+	c.FuzzyHeaderMatch = fuzzy
+	return c
+}
+
+// WithErrorOnDuplicateHeader returns a copy of the options with ErrorOnDuplicateHeader set.
+func (o *Options) WithErrorOnDuplicateHeader(e bool) *Options {
+	c := o.Clone()
+	c.ErrorOnDuplicateHeader = e
+	return c
+}
+
+// WithCollectErrors returns a copy of the options with CollectErrors set.
+func (o *Options) WithCollectErrors(collect bool) *Options {
+	c := o.Clone()
+	c.CollectErrors = collect
+	return c
+}
+
+// WithMaxErrors returns a copy of the options with MaxErrors set.
+func (o *Options) WithMaxErrors(max int) *Options {
+	c := o.Clone()
+	c.MaxErrors = max
+	return c
+}
+
+// WithFieldErrorHandler returns a copy of the options with FieldErrorHandler set.
+func (o *Options) WithFieldErrorHandler(handler func(row int, column, value string, err error) error) *Options {
+	c := o.Clone()
+	c.FieldErrorHandler = handler
+	return c
+}
+
+// WithDefaultValues returns a copy of the options with DefaultValues set.
+func (o *Options) WithDefaultValues(values map[string]string) *Options {
+	c := o.Clone()
+	c.DefaultValues = values
+	return c
+}
+
+// WithColumnTransforms returns a copy of the options with ColumnTransforms set.
+func (o *Options) WithColumnTransforms(transforms map[string]func(string) string) *Options {
+	c := o.Clone()
+	c.ColumnTransforms = transforms
+	return c
+}
+
+// WithOutputTransforms returns a copy of the options with OutputTransforms set.
+func (o *Options) WithOutputTransforms(transforms map[string]func(string) string) *Options {
+	c := o.Clone()
+	c.OutputTransforms = transforms
+	return c
+}
+
+// WithEnumMaps returns a copy of the options with EnumMaps set.
+func (o *Options) WithEnumMaps(maps map[reflect.Type]map[string]int64) *Options {
+	c := o.Clone()
+	c.EnumMaps = maps
+	return c
+}
+
+// WithAllowRaggedRows returns a copy of the options with AllowRaggedRows set.
+func (o *Options) WithAllowRaggedRows(allow bool) *Options {
+	c := o.Clone()
+	c.AllowRaggedRows = allow
+	return c
+}
+
+// WithOmitZeroRows returns a copy of the options with OmitZeroRows set.
+func (o *Options) WithOmitZeroRows(omit bool) *Options {
+	c := o.Clone()
+	c.OmitZeroRows = omit
+	return c
+}
+
+// nilEscape returns the NilEscape, falling back to the default if empty
+func (o *Options) nilEscape() string {
+	if o.NilEscape == "" {
+		return "\\"
+	}
+	return o.NilEscape
+}
+
+// nilCollision reports whether a cell consists of NilEscape prefixes followed by a nil cell,
+// and the number of those prefixes
+func (o *Options) nilCollision(cell string) (int, bool) {
+	esc := o.nilEscape()
+	for n := 0; ; n++ {
+		if o.isNil(cell) {
+			return n, true
+		}
+		if !strings.HasPrefix(cell, esc) {
+			return 0, false
+		}
+		cell = cell[len(esc):]
+	}
+}
+
+// escapeNil escapes a non-nil cell that would be read as nil, if EscapeNilCollision is set
+func (o *Options) escapeNil(cell string) string {
+	if !o.EscapeNilCollision {
+		return cell
+	}
+	if _, ok := o.nilCollision(cell); ok {
+		return o.nilEscape() + cell
+	}
+	return cell
+}
+
+// unescapeNil reverses escapeNil for a cell that is not nil
+func (o *Options) unescapeNil(cell string) string {
+	if !o.EscapeNilCollision {
+		return cell
+	}
+	if n, ok := o.nilCollision(cell); ok && n > 0 {
+		return cell[len(o.nilEscape()):]
+	}
+	return cell
+}
+
+// mapEntrySeparator returns the MapEntrySeparator, falling back to the default if empty
+func (o *Options) mapEntrySeparator() string {
+	if o.MapEntrySeparator == "" {
+		return ";"
+	}
+	return o.MapEntrySeparator
+}
+
+// mapElemOptions returns the options for the keys and values of a map cell.
+// With EscapeMapSeparators, elements that would be read back as nil are escaped too,
+// since the nil check is made on each unescaped element.
+func (o *Options) mapElemOptions() *Options {
+	if !o.EscapeMapSeparators || o.EscapeNilCollision {
+		return o
+	}
+	c := *o
+	c.EscapeNilCollision = true
+	return &c
+}
+
+// mapKVSeparator returns the MapKVSeparator, falling back to the default if empty
+func (o *Options) mapKVSeparator() string {
+	if o.MapKVSeparator == "" {
+		return "="
+	}
+	return o.MapKVSeparator
+}
+
+// forType returns the options to convert a field of type t with,
+// whose NilValue is taken from NilValuesPerKind if the kind of t has an entry
+func (o *Options) forType(t reflect.Type) *Options {
+	if len(o.NilValuesPerKind) == 0 {
+		return o
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	nilValue, ok := o.NilValuesPerKind[t.Kind()]
+	if !ok || nilValue == o.NilValue {
+		return o
+	}
+	c := *o
+	c.NilValue = nilValue
+	return &c
+}
+
+// isNil reports whether a cell represents nil
+func (o *Options) isNil(value string) bool {
+	if o.IsNil != nil {
+		return o.IsNil(value)
+	}
+	return value == o.NilValue
+}
+
+// inputColumn returns the tag corresponding to an incoming header name
+func (o *Options) inputColumn(header string) string {
+	if o.HeaderTransform != nil {
+		header = o.HeaderTransform(header)
+	}
+	if tag, ok := o.HeaderAliases[header]; ok {
+		return tag
+	}
+	for tag, alias := range o.OutputAliases {
+		if alias == header {
+			return tag
+		}
+	}
+	return header
+}
+
+// outputHeader returns the outgoing header name for a tag
+func (o *Options) outputHeader(tag string) string {
+	if alias, ok := o.OutputAliases[tag]; ok {
+		return alias
+	}
+	return tag
+}
+
+// formatFloat formats a float value using FloatFormat and FloatPrecision
+func (o *Options) formatFloat(f float64) string {
+	if o.FloatFormat == 0 {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(f, o.FloatFormat, o.FloatPrecision, 64)
+}
+
+// parseIntBase returns the base passed to strconv.ParseInt and strconv.ParseUint
+func (o *Options) parseIntBase() (int, error) {
+	switch o.IntBase {
+	case 0:
+		return 10, nil
+	case IntBaseAuto:
+		return 0, nil
+	}
+	return o.IntBase, o.checkIntBase()
+}
+
+// formatIntBase returns the base passed to strconv.FormatInt and strconv.FormatUint
+func (o *Options) formatIntBase() (int, error) {
+	if o.IntBase == 0 || o.IntBase == IntBaseAuto {
+		return 10, nil
+	}
+	return o.IntBase, o.checkIntBase()
+}
+
+// checkIntBase returns an error if IntBase is not a valid base, 0 or IntBaseAuto
+func (o *Options) checkIntBase() error {
+	if o.IntBase == 0 || o.IntBase == IntBaseAuto || (o.IntBase >= 2 && o.IntBase <= 36) {
+		return nil
+	}
+	return fmt.Errorf("invalid IntBase: %d", o.IntBase)
+}
+
+// parseIntCell returns the integer cell to pass to strconv.ParseInt and strconv.ParseUint with base.
+// With StrictTypes, an explicit plus sign and leading zeros that the value would lose are errors.
+func (o *Options) parseIntCell(value string, base int) (string, error) {
+	value = o.stripThousands(value)
+	if !o.StrictTypes {
+		return value, nil
+	}
+	if strings.HasPrefix(value, "+") {
+		return "", fmt.Errorf("invalid integer value %q: unexpected plus sign", value)
+	}
+	// With IntBaseAuto, a leading zero is a base prefix
+	if digits := strings.TrimPrefix(value, "-"); base != 0 && len(digits) > 1 && digits[0] == '0' {
+		return "", fmt.Errorf("invalid integer value %q: unexpected leading zero", value)
+	}
+	return value, nil
+}
+
+// stripThousands removes ThousandsSep from a numeric cell
+func (o *Options) stripThousands(value string) string {
+	if o.ThousandsSep == "" {
+		return value
+	}
+	return strings.ReplaceAll(value, o.ThousandsSep, "")
+}
+
+// groupThousands inserts ThousandsSep every three digits of the integer part of a formatted number
+func (o *Options) groupThousands(s string) string {
+	if o.ThousandsSep == "" {
+		return s
+	}
+	start := 0
+	if start < len(s) && (s[0] == '-' || s[0] == '+') {
+		start++
+	}
+	end := start
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	if end-start <= 3 {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.WriteString(s[:start])
+	for i := start; i < end; i++ {
+		if i > start && (end-i)%3 == 0 {
+			sb.WriteString(o.ThousandsSep)
+		}
+		sb.WriteByte(s[i])
+	}
+	sb.WriteString(s[end:])
+	return sb.String()
+}
+
+// formatComplex formats a complex value of the given bit size using FloatFormat and FloatPrecision
+func (o *Options) formatComplex(c complex128, bitSize int) string {
+	if o.FloatFormat == 0 {
+		return strconv.FormatComplex(c, 'f', -1, bitSize)
+	}
+	return strconv.FormatComplex(c, o.FloatFormat, o.FloatPrecision, bitSize)
+}
+
+// formatBigFloat formats a big.Float value using FloatFormat and FloatPrecision
+func (o *Options) formatBigFloat(f *big.Float) string {
+	if o.FloatFormat == 0 {
+		return f.Text('f', -1)
+	}
+	return f.Text(o.FloatFormat, o.FloatPrecision)
+}
+
+// enumMap returns the enum mapping for an integer-kinded type, if any
+func (o *Options) enumMap(t reflect.Type) (map[string]int64, bool) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		m, ok := o.EnumMaps[t]
+		return m, ok
+	}
+	return nil, false
+}
+
+// parseEnum returns the value of an enum name.
+// Integers are accepted too, since values without a name are formatted as numbers.
+func parseEnum(m map[string]int64, t reflect.Type, name string) (int64, error) {
+	if v, ok := m[name]; ok {
+		return v, nil
+	}
+	if v, err := strconv.ParseInt(name, 10, 64); err == nil {
+		return v, nil
+	}
+	return 0, fmt.Errorf("unknown name %q for enum type %v", name, t)
+}
+
+// formatEnum returns the name of an enum value.
+// If several names share the value, the smallest one is used so the output is deterministic.
+func formatEnum(m map[string]int64, v int64) (string, bool) {
+	var (
+		result string
+		found  bool
+	)
+	for name, value := range m {
+		if value == v && (!found || name < result) {
+			result, found = name, true
+		}
+	}
+	return result, found
+}
+
+// parseTime parses a time.Time value using TimeLayout and Location
+func (o *Options) parseTime(value string) (time.Time, error) {
+	layout := o.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	loc := o.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.ParseInLocation(layout, value, loc)
+}
+
+// formatTime formats a time.Time value using TimeLayout and Location
+func (o *Options) formatTime(t time.Time) string {
+	layout := o.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	if o.Location != nil {
+		t = t.In(o.Location)
+	}
+	return t.Format(layout)
+}
+
+// tagName returns the TagName, falling back to the default if empty
+func (o *Options) tagName() string {
+	if o == nil || o.TagName == "" {
+		return tagTable
+	}
+	return o.TagName
+}
+
+// nestedSeparator returns the NestedSeparator, falling back to the default if empty
+func (o *Options) nestedSeparator() string {
+	if o == nil || o.NestedSeparator == "" {
+		return "."
+	}
+	return o.NestedSeparator
+}
+
+// parseBool parses a bool value, accepting the configured BoolFormat strings
+// in addition to the inputs accepted by strconv.ParseBool
+func (f BoolFormat) parseBool(value string) (bool, error) {
+	switch {
+	case f.True != "" && value == f.True:
+		return true, nil
+	case f.False != "" && value == f.False:
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseBool parses a bool value, accepting the BoolTrue and BoolFalse tokens
+// in addition to the inputs accepted by BoolFormat.parseBool
+func (o *Options) parseBool(value string) (bool, error) {
+	for _, token := range o.BoolTrue {
+		if strings.EqualFold(value, token) {
+			return true, nil
+		}
+	}
+	for _, token := range o.BoolFalse {
+		if strings.EqualFold(value, token) {
+			return false, nil
+		}
+	}
+
+	if o.StrictTypes {
+		return o.BoolFormat.parseBoolStrict(value)
+	}
+
+	b, err := o.BoolFormat.parseBool(value)
+	if err != nil && (len(o.BoolTrue) > 0 || len(o.BoolFalse) > 0) {
+		return false, fmt.Errorf("invalid bool value %q: accepted values are %s for true and %s for false, or those of strconv.ParseBool",
+			value, strings.Join(o.BoolTrue, ", "), strings.Join(o.BoolFalse, ", "))
+	}
+	return b, err
+}
+
+// parseBoolStrict parses a bool value, accepting only the strings that formatBool produces
+func (f BoolFormat) parseBoolStrict(value string) (bool, error) {
+	trueValue, falseValue := f.formatBool(true), f.formatBool(false)
+	switch value {
+	case trueValue:
+		return true, nil
+	case falseValue:
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid bool value %q: expected %q or %q", value, trueValue, falseValue)
+}
+
+// formatBool formats a bool value using the configured BoolFormat strings
+func (f BoolFormat) formatBool(b bool) string {
+	if b && f.True != "" {
+		return f.True
+	}
+	if !b && f.False != "" {
+		return f.False
+	}
+	return strconv.FormatBool(b)
+}
+
+// transformOutput applies the OutputTransforms function of a tag, if any, to a formatted cell
+func (o *Options) transformOutput(tag, cell string) string {
+	if transform, ok := o.OutputTransforms[tag]; ok {
+		return transform(cell)
+	}
+	return cell
+}
+
+// tooManyErrors reports whether n collected row errors reach MaxErrors
+func (o *Options) tooManyErrors(n int) bool {
+	return o.MaxErrors > 0 && n >= o.MaxErrors
+}
@@ -0,0 +1,144 @@
+package tablemap_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_Clone(t *testing.T) {
+	t.Run("copy is independent", func(t *testing.T) {
+		base := tablemap.DefaultOptions()
+		clone := base.Clone()
+		clone.NilValue = "NULL"
+
+		assert.Equal(t, "\\N", base.NilValue)
+		assert.Equal(t, "NULL", clone.NilValue)
+	})
+
+	t.Run("nil options", func(t *testing.T) {
+		var base *tablemap.Options
+		assert.Equal(t, tablemap.DefaultOptions(), base.Clone())
+	})
+}
+
+func TestOptions_With(t *testing.T) {
+	base := tablemap.DefaultOptions()
+	aliases := map[string]string{"a": "b"}
+	defaults := map[string]string{"c": "d"}
+	enums := map[reflect.Type]map[string]int64{reflect.TypeOf(0): {"zero": 0}}
+	nilValues := map[reflect.Kind]string{reflect.String: ""}
+
+	derived := base.
+		WithNilValue("NULL").
+		WithNilValuesPerKind(nilValues).
+		WithBoolFormat("1", "0").
+		WithBoolTokens([]string{"yes"}, []string{"no"}).
+		WithStrictTypes(true).
+		WithEscapeNilCollision(true).
+		WithNilEscape("~").
+		WithEmptyStringNotNil(true).
+		WithTagName("csv").
+		WithNestedSeparator("_").
+		WithMapSeparators("|", ":").
+		WithEscapeMapSeparators(true).
+		WithTrimSpace(true).
+		WithFloatFormat('e', 3).
+		WithIntBase(16).
+		WithThousandsSep(",").
+		WithTimeLayout(time.DateTime).
+		WithLocation(time.UTC).
+		WithHeader([]string{"x"}).
+		WithHeaderAliases(aliases).
+		WithOutputAliases(aliases).
+		WithHeaderCaseFold(true).
+		WithFuzzyHeaderMatch(true).
+		WithErrorOnDuplicateHeader(true).
+		WithCollectErrors(true).
+		WithMaxErrors(5).
+		WithDefaultValues(defaults).
+		WithEnumMaps(enums).
+		WithAllowRaggedRows(true).
+		WithOmitZeroRows(true)
+
+	assert.Equal(t, &tablemap.Options{
+		NilValue:               "NULL",
+		NilValuesPerKind:       nilValues,
+		EscapeNilCollision:     true,
+		NilEscape:              "~",
+		EmptyStringNotNil:      true,
+		BoolFormat:             tablemap.BoolFormat{True: "1", False: "0"},
+		BoolTrue:               []string{"yes"},
+		BoolFalse:              []string{"no"},
+		StrictTypes:            true,
+		TagName:                "csv",
+		NestedSeparator:        "_",
+		MapEntrySeparator:      "|",
+		MapKVSeparator:         ":",
+		EscapeMapSeparators:    true,
+		TrimSpace:              true,
+		FloatFormat:            'e',
+		FloatPrecision:         3,
+		IntBase:                16,
+		ThousandsSep:           ",",
+		TimeLayout:             time.DateTime,
+		Location:               time.UTC,
+		Header:                 []string{"x"},
+		HeaderAliases:          aliases,
+		OutputAliases:          aliases,
+		HeaderCaseFold:         true,
+		FuzzyHeaderMatch:       true,
+		ErrorOnDuplicateHeader: true,
+		CollectErrors:          true,
+		MaxErrors:              5,
+		DefaultValues:          defaults,
+		EnumMaps:               enums,
+		AllowRaggedRows:        true,
+		OmitZeroRows:           true,
+	}, derived)
+
+	// The base options are not modified
+	assert.Equal(t, tablemap.DefaultOptions(), base)
+}
+
+func TestOptions_WithIsNil(t *testing.T) {
+	base := tablemap.DefaultOptions()
+	derived := base.WithIsNil(func(value string) bool { return value == "NULL" })
+
+	assert.NotNil(t, derived.IsNil)
+	assert.True(t, derived.IsNil("NULL"))
+	assert.Nil(t, base.IsNil)
+}
+
+func TestOptions_WithNilValue_marshal(t *testing.T) {
+	type Record struct {
+		Value *int `table:"value"`
+	}
+
+	opts := tablemap.DefaultOptions().WithNilValue("NULL")
+	_, data, err := tablemap.MarshalWithOptions([]Record{{}}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"NULL"}}, data)
+}
+
+func TestOptions_WithTransforms(t *testing.T) {
+	base := tablemap.DefaultOptions()
+	derived := base.
+		WithColumnTransforms(map[string]func(string) string{"a": strings.ToUpper}).
+		WithOutputTransforms(map[string]func(string) string{"b": strings.ToLower}).
+		WithHeaderTransform(strings.TrimSpace).
+		WithFieldErrorHandler(func(int, string, string, error) error { return nil })
+
+	assert.Equal(t, "X", derived.ColumnTransforms["a"]("x"))
+	assert.Equal(t, "x", derived.OutputTransforms["b"]("X"))
+	assert.Equal(t, "c", derived.HeaderTransform(" c "))
+	assert.Nil(t, base.ColumnTransforms)
+	assert.Nil(t, base.OutputTransforms)
+	assert.Nil(t, base.HeaderTransform)
+	assert.NotNil(t, derived.FieldErrorHandler)
+	assert.Nil(t, base.FieldErrorHandler)
+}
@@ -0,0 +1,73 @@
+package tablemap
+
+import (
+	"errors"
+	"io"
+)
+
+// RowScanner is a source of table rows.
+// Scan returns the next row, or io.EOF when there are no more rows.
+type RowScanner interface {
+	Scan() ([]string, error)
+}
+
+// RowScannerFunc adapts a function to a RowScanner.
+// For example, RowScannerFunc(csvReader.Read) scans the records of a csv.Reader.
+type RowScannerFunc func() ([]string, error)
+
+// Scan calls f.
+func (f RowScannerFunc) Scan() ([]string, error) {
+	return f()
+}
+
+// ScanAll reads all rows from s and converts them to a slice of struct T.
+// The first row is the header. An empty source returns no rows.
+// Errors are reported as with UnmarshalWithOptions, with Row counting data rows after the header.
+func ScanAll[T any](s RowScanner, opts *Options) ([]T, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	var result []T
+
+	header, err := s.Scan()
+	if err == io.EOF {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err := NewRowHandler[T](header, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for i := 0; ; i++ {
+		data, err := s.Scan()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := handler.UnmarshalRow(data)
+		if err != nil {
+			rowErr := &UnmarshalError{Row: i, Err: err}
+			if !opts.CollectErrors {
+				return nil, rowErr
+			}
+			// Skip the bad row and keep going, up to MaxErrors
+			errs = append(errs, rowErr)
+			if opts.tooManyErrors(len(errs)) {
+				break
+			}
+			continue
+		}
+		result = append(result, *record)
+	}
+
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,120 @@
+package tablemap_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+// sliceScanner scans rows from a slice
+type sliceScanner struct {
+	rows [][]string
+	err  error // returned once the rows are exhausted, io.EOF if nil
+}
+
+func (s *sliceScanner) Scan() ([]string, error) {
+	if len(s.rows) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	row := s.rows[0]
+	s.rows = s.rows[1:]
+	return row, nil
+}
+
+func TestScanAll(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	errScan := errors.New("scan failed")
+
+	tests := []struct {
+		name     string
+		scanner  tablemap.RowScanner
+		opts     *tablemap.Options
+		expected []Person
+		wantErr  string
+	}{
+		{
+			name: "rows",
+			scanner: &sliceScanner{rows: [][]string{
+				{"age", "name"},
+				{"23", "Alice"},
+				{"25", "Bob"},
+			}},
+			expected: []Person{{Name: "Alice", Age: 23}, {Name: "Bob", Age: 25}},
+		},
+		{
+			name:     "header only",
+			scanner:  &sliceScanner{rows: [][]string{{"name", "age"}}},
+			expected: nil,
+		},
+		{
+			name:     "empty source",
+			scanner:  &sliceScanner{},
+			expected: nil,
+		},
+		{
+			name:     "csv.Reader",
+			scanner:  tablemap.RowScannerFunc(csv.NewReader(strings.NewReader("name,age\nAlice,23\n")).Read),
+			expected: []Person{{Name: "Alice", Age: 23}},
+		},
+		{
+			name: "invalid row",
+			scanner: &sliceScanner{rows: [][]string{
+				{"name", "age"},
+				{"Alice", "23"},
+				{"Bob", "abc"},
+			}},
+			wantErr: `row 1: setting field age: strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+		{
+			name: "collect errors",
+			scanner: &sliceScanner{rows: [][]string{
+				{"name", "age"},
+				{"Alice", "abc"},
+				{"Bob", "25"},
+			}},
+			opts:     tablemap.DefaultOptions().WithCollectErrors(true),
+			expected: []Person{{Name: "Bob", Age: 25}},
+			wantErr:  `row 0: setting field age: strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+		{
+			name: "max errors",
+			scanner: &sliceScanner{rows: [][]string{
+				{"name", "age"},
+				{"Alice", "abc"},
+				{"Bob", "25"},
+			}},
+			opts:     tablemap.DefaultOptions().WithCollectErrors(true).WithMaxErrors(1),
+			expected: nil,
+			wantErr:  `row 0: setting field age: strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+		{
+			name:    "scan error",
+			scanner: &sliceScanner{rows: [][]string{{"name", "age"}}, err: errScan},
+			wantErr: "scan failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tablemap.ScanAll[Person](tt.scanner, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package tablemap
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Column describes a single column of a Schema.
+type Column struct {
+	Name string
+	Kind reflect.Kind // Kind of the struct field as declared
+	Type reflect.Type // Type of the struct field as declared
+}
+
+// Schema describes the ordered columns produced by marshaling a struct type.
+type Schema struct {
+	Columns []Column
+}
+
+// SchemaOf returns the schema of struct type T using default options.
+// If T is not a struct or pointer to struct, the returned schema has no columns.
+// It is an error for the fields of T to be invalid for marshaling, such as a tagged unexported field.
+func SchemaOf[T any]() (Schema, error) {
+	return SchemaOfWithOptions[T](DefaultOptions())
+}
+
+// SchemaOfWithOptions returns the schema of struct type T with custom options.
+// If T is not a struct or pointer to struct, the returned schema has no columns.
+func SchemaOfWithOptions[T any](opts *Options) (Schema, error) {
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return Schema{}, nil
+	}
+	r, err := newRow(t, nil, opts)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	columns := make([]Column, len(r.header))
+	for i, name := range r.header {
+		info := r.fields[r.columns[i]]
+		ft := t.FieldByIndex(info.index).Type
+		columns[i] = Column{
+			Name: name,
+			Kind: ft.Kind(),
+			Type: ft,
+		}
+	}
+	return Schema{Columns: columns}, nil
+}
+
+// Names returns the ordered column names of the schema.
+func (s Schema) Names() []string {
+	names := make([]string, len(s.Columns))
+	for i, c := range s.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Compatible checks whether header matches the schema exactly.
+// It returns a *SchemaMismatchError describing missing, extra and reordered columns otherwise.
+func (s Schema) Compatible(header []string) error {
+	names := s.Names()
+
+	e := &SchemaMismatchError{}
+	for _, name := range names {
+		if !slices.Contains(header, name) {
+			e.Missing = append(e.Missing, name)
+		}
+	}
+	for _, h := range header {
+		if !slices.Contains(names, h) {
+			e.Extra = append(e.Extra, h)
+		}
+	}
+
+	// Compare the relative order of the columns present in both
+	var expected, actual []string
+	for _, name := range names {
+		if slices.Contains(header, name) {
+			expected = append(expected, name)
+		}
+	}
+	for _, h := range header {
+		if slices.Contains(names, h) {
+			actual = append(actual, h)
+		}
+	}
+	e.Reordered = !slices.Equal(expected, actual)
+
+	if len(e.Missing) == 0 && len(e.Extra) == 0 && !e.Reordered {
+		return nil
+	}
+	return e
+}
+
+// SchemaMismatchError describes the differences between a Schema and a header.
+type SchemaMismatchError struct {
+	Missing   []string // Columns in the schema but not in the header
+	Extra     []string // Columns in the header but not in the schema
+	Reordered bool     // Whether the common columns appear in a different order
+}
+
+func (e *SchemaMismatchError) Error() string {
+	var msgs []string
+	if len(e.Missing) > 0 {
+		msgs = append(msgs, fmt.Sprintf("missing columns: %s", strings.Join(e.Missing, ", ")))
+	}
+	if len(e.Extra) > 0 {
+		msgs = append(msgs, fmt.Sprintf("extra columns: %s", strings.Join(e.Extra, ", ")))
+	}
+	if e.Reordered {
+		msgs = append(msgs, "columns are reordered")
+	}
+	return "schema mismatch: " + strings.Join(msgs, "; ")
+}
@@ -0,0 +1,120 @@
+package tablemap_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaTestStruct struct {
+	ID       int       `table:"id"`
+	Name     *string   `table:"name"`
+	Score    float64   `table:"score"`
+	Customer *Customer `table:"customer"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema, err := tablemap.SchemaOf[schemaTestStruct]()
+	assert.NoError(t, err)
+	assert.Equal(t, tablemap.Schema{
+		Columns: []tablemap.Column{
+			{Name: "id", Kind: reflect.Int, Type: reflect.TypeFor[int]()},
+			{Name: "name", Kind: reflect.Ptr, Type: reflect.TypeFor[*string]()},
+			{Name: "score", Kind: reflect.Float64, Type: reflect.TypeFor[float64]()},
+			{Name: "customer.name", Kind: reflect.String, Type: reflect.TypeFor[string]()},
+			{Name: "customer.email", Kind: reflect.String, Type: reflect.TypeFor[string]()},
+		},
+	}, schema)
+	assert.Equal(t, []string{"id", "name", "score", "customer.name", "customer.email"}, schema.Names())
+
+	ptrSchema, err := tablemap.SchemaOf[*schemaTestStruct]()
+	assert.NoError(t, err)
+	assert.Equal(t, schema, ptrSchema)
+
+	intSchema, err := tablemap.SchemaOf[int]()
+	assert.NoError(t, err)
+	assert.Empty(t, intSchema.Columns)
+}
+
+func TestSchemaOf_invalidFields(t *testing.T) {
+	type Unexported struct {
+		x int `table:"x"`
+	}
+	_, err := tablemap.SchemaOf[Unexported]()
+	assert.Error(t, err)
+
+	type BadIndex struct {
+		X int `table:"x,index=2"`
+	}
+	_, err = tablemap.SchemaOf[BadIndex]()
+	assert.EqualError(t, err, "column x: index 2 is out of range for 1 columns")
+}
+
+func TestSchemaOfWithOptions(t *testing.T) {
+	opts := tablemap.DefaultOptions().
+		WithNestedSeparator("_").
+		WithOutputAliases(map[string]string{"id": "ID"})
+
+	schema, err := tablemap.SchemaOfWithOptions[schemaTestStruct](opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ID", "name", "score", "customer_name", "customer_email"}, schema.Names())
+}
+
+func TestSchema_Compatible(t *testing.T) {
+	schema, err := tablemap.SchemaOf[schemaTestStruct]()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		header   []string
+		expected *tablemap.SchemaMismatchError
+		message  string
+	}{
+		{
+			name:   "exact match",
+			header: []string{"id", "name", "score", "customer.name", "customer.email"},
+		},
+		{
+			name:     "missing columns",
+			header:   []string{"id", "name", "customer.email"},
+			expected: &tablemap.SchemaMismatchError{Missing: []string{"score", "customer.name"}},
+			message:  "schema mismatch: missing columns: score, customer.name",
+		},
+		{
+			name:     "extra columns",
+			header:   []string{"id", "name", "score", "customer.name", "customer.email", "memo"},
+			expected: &tablemap.SchemaMismatchError{Extra: []string{"memo"}},
+			message:  "schema mismatch: extra columns: memo",
+		},
+		{
+			name:     "reordered columns",
+			header:   []string{"name", "id", "score", "customer.name", "customer.email"},
+			expected: &tablemap.SchemaMismatchError{Reordered: true},
+			message:  "schema mismatch: columns are reordered",
+		},
+		{
+			name:   "all differences",
+			header: []string{"score", "id", "memo"},
+			expected: &tablemap.SchemaMismatchError{
+				Missing:   []string{"name", "customer.name", "customer.email"},
+				Extra:     []string{"memo"},
+				Reordered: true,
+			},
+			message: "schema mismatch: missing columns: name, customer.name, customer.email; extra columns: memo; columns are reordered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Compatible(tt.header)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.message)
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
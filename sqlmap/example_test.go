@@ -0,0 +1,32 @@
+package sqlmap_test
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/kmio11/tablemap/sqlmap"
+)
+
+func ExampleScanAll() {
+	type User struct {
+		ID    int     `table:"id"`
+		Name  string  `table:"name"`
+		Email *string `table:"email"` // nil for NULL
+	}
+
+	var db *sql.DB // opened with sql.Open
+
+	rows, err := db.Query("SELECT id, name, email FROM users")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer rows.Close()
+
+	users, err := sqlmap.ScanAll[User](rows, nil)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println(len(users))
+}
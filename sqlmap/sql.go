@@ -0,0 +1,64 @@
+package sqlmap
+
+import (
+	"database/sql"
+	"io"
+
+	"github.com/kmio11/tablemap"
+)
+
+// ScanAll reads all rows of a query result and converts them to a slice of struct T.
+// The column names of rows are used as the header, and values are converted to strings
+// as with sql.Rows.Scan into a string. NULL values are read as the nil value of opts.
+// Errors are reported as with tablemap.ScanAll. ScanAll does not close rows.
+func ScanAll[T any](rows *sql.Rows, opts *tablemap.Options) ([]T, error) {
+	if opts == nil {
+		opts = tablemap.DefaultOptions()
+	}
+	s := &scanner{rows: rows, nilValue: opts.NilValue}
+	return tablemap.ScanAll[T](s, opts)
+}
+
+// scanner adapts sql.Rows to a tablemap.RowScanner, scanning the column names first
+type scanner struct {
+	rows     *sql.Rows
+	nilValue string
+	values   []sql.NullString
+	dest     []any
+}
+
+// Scan returns the column names on the first call, and the values of the next row afterwards
+func (s *scanner) Scan() ([]string, error) {
+	if s.dest == nil {
+		columns, err := s.rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		s.values = make([]sql.NullString, len(columns))
+		s.dest = make([]any, len(columns))
+		for i := range s.values {
+			s.dest[i] = &s.values[i]
+		}
+		return columns, nil
+	}
+
+	if !s.rows.Next() {
+		if err := s.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if err := s.rows.Scan(s.dest...); err != nil {
+		return nil, err
+	}
+
+	row := make([]string, len(s.values))
+	for i, v := range s.values {
+		if v.Valid {
+			row[i] = v.String
+		} else {
+			row[i] = s.nilValue
+		}
+	}
+	return row, nil
+}
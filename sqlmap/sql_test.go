@@ -0,0 +1,131 @@
+package sqlmap_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kmio11/tablemap"
+	"github.com/kmio11/tablemap/sqlmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver serves a fixed result set for any query
+type fakeDriver struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return &fakeStmt{c.d}, nil }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type fakeStmt struct{ d *fakeDriver }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{d: s.d}, nil
+}
+
+type fakeRows struct {
+	d *fakeDriver
+	i int
+}
+
+func (r *fakeRows) Columns() []string { return r.d.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.d.rows) {
+		return io.EOF
+	}
+	copy(dest, r.d.rows[r.i])
+	r.i++
+	return nil
+}
+
+// query registers a fake driver with the given result set and runs a query against it
+func query(t *testing.T, columns []string, rows [][]driver.Value) *sql.Rows {
+	t.Helper()
+	name := "fake-" + t.Name()
+	sql.Register(name, &fakeDriver{columns: columns, rows: rows})
+	db, err := sql.Open(name, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	result, err := db.Query("SELECT")
+	require.NoError(t, err)
+	t.Cleanup(func() { result.Close() })
+	return result
+}
+
+type Person struct {
+	Name      string    `table:"name"`
+	Age       *int      `table:"age"`
+	Active    bool      `table:"active"`
+	Score     float64   `table:"score"`
+	CreatedAt time.Time `table:"created_at"`
+}
+
+func P[T any](t T) *T {
+	return &t
+}
+
+func TestScanAll(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	columns := []string{"name", "age", "active", "score", "created_at", "extra"}
+
+	t.Run("rows", func(t *testing.T) {
+		rows := query(t, columns, [][]driver.Value{
+			{[]byte("Alice"), int64(23), true, 1.5, created, "x"},
+			{"Bob", nil, false, float64(0), created, nil},
+		})
+
+		result, err := sqlmap.ScanAll[Person](rows, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []Person{
+			{Name: "Alice", Age: P(23), Active: true, Score: 1.5, CreatedAt: created},
+			{Name: "Bob", Age: nil, Active: false, Score: 0, CreatedAt: created},
+		}, result)
+	})
+
+	t.Run("custom nil value", func(t *testing.T) {
+		rows := query(t, []string{"name", "age"}, [][]driver.Value{
+			{"Alice", nil},
+		})
+
+		result, err := sqlmap.ScanAll[Person](rows, tablemap.DefaultOptions().WithNilValue("NULL"))
+		require.NoError(t, err)
+		assert.Equal(t, []Person{{Name: "Alice", Age: nil}}, result)
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		rows := query(t, columns, nil)
+
+		result, err := sqlmap.ScanAll[Person](rows, nil)
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("conversion error", func(t *testing.T) {
+		rows := query(t, []string{"name", "age"}, [][]driver.Value{
+			{"Alice", "old"},
+		})
+
+		_, err := sqlmap.ScanAll[Person](rows, nil)
+		var unmarshalErr *tablemap.UnmarshalError
+		require.ErrorAs(t, err, &unmarshalErr)
+		assert.Equal(t, 0, unmarshalErr.Row)
+	})
+}
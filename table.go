@@ -1,10 +1,22 @@
 package tablemap
 
 import (
+	"bytes"
 	"encoding"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
 	"reflect"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
 // CellMarshaler is the interface implemented by types that
@@ -19,23 +31,15 @@ type CellUnmarshaler interface {
 	UnmarshalCell(string) error
 }
 
-// Options defines configuration options for marshaling and unmarshaling.
-type Options struct {
-	// NilValue is the string representation of nil values.
-	// Default is "\N".
-	NilValue string
-}
-
-// DefaultOptions returns the default options.
-func DefaultOptions() *Options {
-	return &Options{
-		NilValue: "\\N",
-	}
-}
-
 const (
-	tagTable = "table"
-	ignore   = "-"
+	tagTable       = "table"
+	ignore         = "-"
+	tagOptJSON     = "json"
+	tagOptInline   = "inline"
+	tagOptRequired = "required"
+	tagOptDefault  = "default="
+	tagOptIndex    = "index="
+	tagOptLayout   = "layout="
 )
 
 // Unmarshal converts table data into a slice of structs using default options.
@@ -44,6 +48,17 @@ func Unmarshal(header []string, data [][]string, v any) error {
 }
 
 // UnmarshalWithOptions converts table data into a slice of structs with custom options.
+// v must be a pointer to a slice of structs or a slice of pointers to structs.
+// Elements with several levels of pointers, such as **T, are also accepted,
+// and every level is newly allocated for each row.
+// v may also be a pointer to an array, which is filled from the start;
+// it is an error for data to have more rows than the array, and remaining elements are left unchanged.
+// A failure to unmarshal a row is reported as an *UnmarshalError.
+// If opts.CollectErrors is set, bad rows are skipped and v is populated with the
+// successfully parsed rows even when an error is returned.
+// If opts.MaxErrors is also set, unmarshaling stops once that many rows have failed.
+// v may also be a pointer to a slice of map[string]string or map[string]any,
+// in which case each row is stored as a map keyed by header with raw string values.
 func UnmarshalWithOptions(header []string, data [][]string, v any, opts *Options) error {
 	if opts == nil {
 		opts = DefaultOptions()
@@ -55,37 +70,186 @@ func UnmarshalWithOptions(header []string, data [][]string, v any, opts *Options
 	}
 
 	sliceVal := rv.Elem()
-	if sliceVal.Kind() != reflect.Slice {
-		return fmt.Errorf("v must be a pointer to a slice")
+	isArray := sliceVal.Kind() == reflect.Array
+	if sliceVal.Kind() != reflect.Slice && !isArray {
+		return fmt.Errorf("v must be a pointer to a slice or array")
+	}
+	if isArray && len(data) > sliceVal.Len() {
+		return fmt.Errorf("data has %d rows, exceeding the array length %d", len(data), sliceVal.Len())
+	}
+
+	if header == nil && opts.Header != nil {
+		header = opts.Header
 	}
 
 	// Get the type of elements in the slice
 	sliceElemType := sliceVal.Type().Elem()
-	if sliceElemType.Kind() != reflect.Struct {
-		return fmt.Errorf("slice elements must be structs")
+
+	// Maps are populated directly from the header and rows
+	if sliceElemType.Kind() == reflect.Map {
+		if isArray {
+			return fmt.Errorf("arrays of maps are not supported")
+		}
+		return unmarshalMaps(header, data, sliceVal, opts)
+	}
+
+	// Elements may be pointers to structs, in which case each row is allocated
+	structType, depth := indirectType(sliceElemType)
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("slice elements must be structs or pointers to structs")
 	}
 
 	// Create row handler for processing
-	r, err := newRow(sliceElemType, header, opts)
+	r, err := newRow(structType, header, opts)
 	if err != nil {
 		return err
 	}
+	if err := r.checkRequiredColumns(); err != nil {
+		return err
+	}
 
 	// Process each row
+	var errs []error
+	n := 0 // Number of rows stored
+	for i, rowData := range data {
+		// Create new struct
+		newStruct := reflect.New(structType)
+
+		// Use row.unmarshalRow to fill the struct
+		if err := r.unmarshalRow(i, rowData, newStruct.Interface()); err != nil {
+			rowErr := &UnmarshalError{Row: i, Err: err}
+			if !opts.CollectErrors {
+				return rowErr
+			}
+			// Skip the bad row and keep going, up to MaxErrors
+			errs = append(errs, rowErr)
+			if opts.tooManyErrors(len(errs)) {
+				break
+			}
+			continue
+		}
+
+		elem := newStruct
+		if depth == 0 {
+			elem = newStruct.Elem()
+		}
+		// Allocate the outer pointers of multi-level elements such as **T
+		for d := 1; d < depth; d++ {
+			p := reflect.New(elem.Type())
+			p.Elem().Set(elem)
+			elem = p
+		}
+		if isArray {
+			sliceVal.Index(n).Set(elem)
+		} else {
+			sliceVal.Set(reflect.Append(sliceVal, elem))
+		}
+		n++
+	}
+
+	return errors.Join(errs...)
+}
+
+// UnmarshalPositional converts table data without a header into a slice of structs with custom options.
+// Cells are mapped by position to the columns of opts.Header if set,
+// or to the columns of the struct in declaration order otherwise.
+// v is as for UnmarshalWithOptions, except that slices of maps are not supported.
+func UnmarshalPositional(data [][]string, v any, opts *Options) error {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.Header != nil {
+		return UnmarshalWithOptions(opts.Header, data, v, opts)
+	}
+
+	structType, err := targetStructType(v)
+	if err != nil {
+		return err
+	}
+
+	r, err := newRow(structType, nil, opts)
+	if err != nil {
+		return err
+	}
+	return UnmarshalWithOptions(r.header, data, v, opts)
+}
+
+// targetStructType returns the struct type of the elements of v,
+// which must be a pointer to a slice or array of structs or pointers to structs
+func targetStructType(v any) (reflect.Type, error) {
+	rt := reflect.TypeOf(v)
+	if rt == nil || rt.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("v must be a non-nil pointer to a slice")
+	}
+	if k := rt.Elem().Kind(); k != reflect.Slice && k != reflect.Array {
+		return nil, fmt.Errorf("v must be a pointer to a slice or array")
+	}
+	structType, _ := indirectType(rt.Elem().Elem())
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("slice elements must be structs or pointers to structs")
+	}
+	return structType, nil
+}
+
+// indirectType returns the type reached by dereferencing t until it is not a pointer,
+// and the number of pointer levels dereferenced
+func indirectType(t reflect.Type) (reflect.Type, int) {
+	depth := 0
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		depth++
+	}
+	return t, depth
+}
+
+// UnmarshalError describes a failure to unmarshal a single row.
+type UnmarshalError struct {
+	Row int // Index of the row in data
+	Err error
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// unmarshalMaps converts table data into a slice of maps keyed by header.
+// The map key must be a string type and the map value must be a string type or an empty interface.
+// Cells are stored as raw strings.
+func unmarshalMaps(header []string, data [][]string, sliceVal reflect.Value, opts *Options) error {
+	mapType := sliceVal.Type().Elem()
+	keyType := mapType.Key()
+	elemType := mapType.Elem()
+	if keyType.Kind() != reflect.String {
+		return fmt.Errorf("map keys must be strings")
+	}
+	if elemType.Kind() != reflect.String && !(elemType.Kind() == reflect.Interface && elemType.NumMethod() == 0) {
+		return fmt.Errorf("map values must be strings or empty interfaces")
+	}
+
 	for _, rowData := range data {
 		if len(rowData) != len(header) {
-			return fmt.Errorf("inconsistent data length")
+			if !opts.AllowRaggedRows {
+				return fmt.Errorf("inconsistent data length")
+			}
+			// Ignore extra cells beyond the header
+			rowData = rowData[:min(len(rowData), len(header))]
 		}
 
-		// Create new struct
-		newStruct := reflect.New(sliceElemType)
-
-		// Use row.unmarshalRow to fill the struct
-		if err := r.unmarshalRow(rowData, newStruct.Interface()); err != nil {
-			return err
+		m := reflect.MakeMapWithSize(mapType, len(header))
+		for i, col := range rowData {
+			key := reflect.ValueOf(header[i]).Convert(keyType)
+			value := reflect.ValueOf(col)
+			if elemType.Kind() == reflect.String {
+				value = value.Convert(elemType)
+			}
+			m.SetMapIndex(key, value)
 		}
 
-		sliceVal.Set(reflect.Append(sliceVal, newStruct.Elem()))
+		sliceVal.Set(reflect.Append(sliceVal, m))
 	}
 
 	return nil
@@ -97,81 +261,204 @@ func Marshal(v any) ([]string, [][]string, error) {
 }
 
 // MarshalWithOptions converts a slice of structs into table data with custom options.
+// v may also be an array of structs.
+// For an empty slice, the header is still returned along with empty data.
 func MarshalWithOptions(v any, opts *Options) ([]string, [][]string, error) {
+	return marshal(v, nil, opts)
+}
+
+// MarshalOrdered converts a slice of structs into table data with the columns given by header.
+// header may list any subset of the struct's tags (or their OutputAliases) in any order.
+// It is an error for header to contain a column that does not map to a field.
+func MarshalOrdered(v any, header []string, opts *Options) ([]string, [][]string, error) {
+	if header == nil {
+		header = []string{}
+	}
+	return marshal(v, slices.Clone(header), opts)
+}
+
+// MarshalAppend converts a slice of structs into table data with custom options,
+// appending the rows to dst and returning the header and the extended slice.
+// This allows concatenating many batches of the same type under a shared header
+// without allocating intermediate row slices.
+// On error, dst is returned unchanged, so the rows collected so far are kept.
+func MarshalAppend(dst [][]string, v any, opts *Options) ([]string, [][]string, error) {
+	return marshalAppend(dst, v, nil, opts)
+}
+
+// marshal converts a slice or array of structs into table data.
+// If header is nil, all columns are marshaled in declaration order.
+func marshal(v any, header []string, opts *Options) ([]string, [][]string, error) {
+	header, data, err := marshalAppend([][]string{}, v, header, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, data, nil
+}
+
+// marshalAppend converts a slice or array of structs into table data, appending the rows to dst.
+// If header is nil, all columns are marshaled in declaration order. On error, dst is returned unchanged.
+func marshalAppend(dst [][]string, v any, header []string, opts *Options) ([]string, [][]string, error) {
 	if opts == nil {
 		opts = DefaultOptions()
 	}
 
 	rv := reflect.ValueOf(v)
-	if rv.Kind() != reflect.Slice {
-		return nil, nil, fmt.Errorf("v must be a slice")
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, dst, fmt.Errorf("v must be a slice or array")
 	}
 
-	if rv.Len() == 0 {
-		return nil, nil, nil
-	}
-
-	// Get the type of elements in the slice
+	// Get the type of elements in the slice.
+	// This is available even when the slice is empty, so the header is always returned.
 	elemType := rv.Type().Elem()
+	if elemType.Kind() == reflect.Interface {
+		// The header is derived from the element type, so rows of mixed concrete types cannot share it
+		return nil, dst, fmt.Errorf("slice elements must be structs, got interface type %v; use a slice of a concrete struct type", elemType)
+	}
 	if elemType.Kind() != reflect.Struct {
-		return nil, nil, fmt.Errorf("slice elements must be structs")
+		return nil, dst, fmt.Errorf("slice elements must be structs")
 	}
 
-	r, err := newRow(elemType, nil, opts)
+	r, err := newRow(elemType, header, opts)
 	if err != nil {
-		return nil, nil, err
+		return nil, dst, err
+	}
+	for i, col := range r.columns {
+		if _, ok := r.fields[col]; !ok {
+			return nil, dst, fmt.Errorf("unknown column: %s", r.header[i])
+		}
 	}
 
 	// Create data rows
-	data := make([][]string, rv.Len())
+	data := slices.Grow(dst, rv.Len())
 	for i := 0; i < rv.Len(); i++ {
+		if opts.OmitZeroRows && r.isZero(rv.Index(i)) {
+			continue
+		}
 		row, err := r.marshalRow(rv.Index(i).Interface())
 		if err != nil {
-			return nil, nil, err
+			return nil, dst, err
 		}
-		data[i] = row
+		data = append(data, row)
 	}
 
 	return r.header, data, nil
 }
 
+// MarshalVertical converts a single struct into field/value pairs with custom options.
+// v must be a struct, a pointer to a struct, or a slice containing exactly one struct.
+// This is useful for displaying a single record vertically.
+func MarshalVertical(v any, opts *Options) ([][2]string, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		if rv.Len() != 1 {
+			return nil, fmt.Errorf("v must contain exactly one element, got %d", rv.Len())
+		}
+		v = rv.Index(0).Interface()
+	}
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newRow(rv.Type(), nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := r.marshalRow(rv.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([][2]string, len(r.header))
+	for i, tag := range r.header {
+		pairs[i] = [2]string{tag, row[i]}
+	}
+
+	return pairs, nil
+}
+
 // fieldInfo stores information about a struct field including its path through embedded structs
 type fieldInfo struct {
 	index    []int
 	tag      string
-	position int // Field position to maintain declaration order
+	position int    // Column position, following declaration order unless pinned with the index tag option
+	json     bool   // Whether the cell is encoded as JSON
+	required bool   // Whether an empty or nil cell is an error when unmarshaling
+	layout   string // Value of the layout tag option, which overrides Options.TimeLayout
+
+	defaultValue string // Value of the default tag option, used for empty or nil cells
+	hasDefault   bool
+
+	viaPointer bool // Whether the field is reached through a nested or embedded pointer to struct
 }
 
 // fieldMap contains the result of field mapping
 type fieldMap struct {
 	fields      map[string]fieldInfo
 	orderedTags []string
+	unexported  []string // Unexported fields with a table tag, which are an error
+	err         error    // Invalid tag options, which are reported when the type is used
 }
 
 // getFieldMap creates a map of tag names to field paths and maintains declaration order
-func getFieldMap(t reflect.Type) fieldMap {
+func getFieldMap(t reflect.Type, opts *Options) fieldMap {
+	root := t
 	result := fieldMap{
 		fields:      make(map[string]fieldInfo),
 		orderedTags: make([]string, 0),
 	}
 
 	pos := 0
+	sep := opts.nestedSeparator()
+	tagName := opts.tagName()
+
+	// visiting holds the nested struct types on the current path to avoid infinite recursion
+	visiting := map[reflect.Type]bool{t: true}
 
-	var addFields func(t reflect.Type, index []int, isEmbedded bool)
-	addFields = func(t reflect.Type, index []int, isEmbedded bool) {
+	// pinned holds the explicit column index of tags with the index tag option
+	pinned := make(map[string]int)
+
+	var addFields func(t reflect.Type, index []int, isEmbedded bool, prefix string)
+	addFields = func(t reflect.Type, index []int, isEmbedded bool, prefix string) {
 		for i := 0; i < t.NumField(); i++ {
 			field := t.Field(i)
-			currIndex := append(index, i)
-
-			// Handle embedded struct
-			if field.Anonymous && field.Type.Kind() == reflect.Struct {
-				addFields(field.Type, currIndex, true)
+			currIndex := append(slices.Clone(index), i)
+
+			// Promote the fields of embedded and inline structs without a prefix.
+			// The embedded struct itself may be unexported: its exported fields are still
+			// settable through reflection, just as they are promoted in Go.
+			if promoted, ok := promotedStructType(field, tagName); ok {
+				if !visiting[promoted] {
+					visiting[promoted] = true
+					addFields(promoted, currIndex, true, prefix)
+					delete(visiting, promoted)
+				}
 				continue
 			}
 
 			// Skip fields without table tag
-			tag := field.Tag.Get(tagTable)
-			if tag == "" || tag == ignore {
+			tag, tagOpts, ok := lookupTag(field, tagName)
+			if !ok {
+				continue
+			}
+			// Unexported fields cannot be set through reflection
+			if !field.IsExported() {
+				result.unexported = append(result.unexported, t.String()+"."+field.Name)
+				continue
+			}
+			tag = prefix + tag
+			isJSON := tagOpts.Contains(tagOptJSON)
+
+			// Flatten nested struct fields with the tag as a prefix
+			if nested, ok := nestedStructType(field.Type, tagName); ok && !isJSON && !visiting[nested] {
+				visiting[nested] = true
+				addFields(nested, currIndex, isEmbedded, tag+sep)
+				delete(visiting, nested)
 				continue
 			}
 
@@ -181,10 +468,30 @@ func getFieldMap(t reflect.Type) fieldMap {
 			}
 
 			// Update field info
+			defaultValue, hasDefault := tagOpts.Default()
+			layout, _ := tagOpts.Value(tagOptLayout)
 			result.fields[tag] = fieldInfo{
-				index:    currIndex,
-				tag:      tag,
-				position: pos,
+				index:        currIndex,
+				tag:          tag,
+				position:     pos,
+				json:         isJSON,
+				required:     tagOpts.Contains(tagOptRequired),
+				layout:       layout,
+				viaPointer:   viaPointer(root, currIndex),
+				defaultValue: defaultValue,
+				hasDefault:   hasDefault,
+			}
+
+			delete(pinned, tag)
+			if value, ok := tagOpts.Value(tagOptIndex); ok {
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 0 {
+					if result.err == nil {
+						result.err = fmt.Errorf("column %s: invalid index option: %q", tag, value)
+					}
+				} else {
+					pinned[tag] = n
+				}
 			}
 
 			// Update orderedTags
@@ -197,10 +504,201 @@ func getFieldMap(t reflect.Type) fieldMap {
 		}
 	}
 
-	addFields(t, nil, false)
+	addFields(t, nil, false, "")
+	if len(pinned) > 0 && result.err == nil {
+		result.err = result.pinColumns(pinned)
+	}
 	return result
 }
 
+// pinColumns moves the pinned tags to their explicit index, filling the remaining
+// positions with the other tags in declaration order
+func (fm *fieldMap) pinColumns(pinned map[string]int) error {
+	ordered := make([]string, len(fm.orderedTags))
+	for _, tag := range fm.orderedTags {
+		i, ok := pinned[tag]
+		if !ok {
+			continue
+		}
+		if i >= len(ordered) {
+			return fmt.Errorf("column %s: index %d is out of range for %d columns", tag, i, len(ordered))
+		}
+		if ordered[i] != "" {
+			return fmt.Errorf("columns %s and %s both have index %d", ordered[i], tag, i)
+		}
+		ordered[i] = tag
+	}
+
+	free := 0
+	for _, tag := range fm.orderedTags {
+		if _, ok := pinned[tag]; ok {
+			continue
+		}
+		for ordered[free] != "" {
+			free++
+		}
+		ordered[free] = tag
+	}
+
+	fm.orderedTags = ordered
+	for i, tag := range ordered {
+		info := fm.fields[tag]
+		info.position = i
+		fm.fields[tag] = info
+	}
+	return nil
+}
+
+var (
+	cellMarshalerType   = reflect.TypeOf((*CellMarshaler)(nil)).Elem()
+	cellUnmarshalerType = reflect.TypeOf((*CellUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	bigFloatType        = reflect.TypeOf(big.Float{})
+	timeType            = reflect.TypeOf(time.Time{})
+	urlType             = reflect.TypeOf(url.URL{})
+	ipNetType           = reflect.TypeOf(net.IPNet{})
+)
+
+// nestedStructType returns the struct type of a field that should be flattened into columns.
+// A field is flattened if it is a struct or pointer to struct which has tagged fields
+// and does not marshal itself into a single cell.
+func nestedStructType(t reflect.Type, tagName string) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	pt := reflect.PointerTo(t)
+	for _, it := range []reflect.Type{cellMarshalerType, cellUnmarshalerType, textMarshalerType, textUnmarshalerType} {
+		if pt.Implements(it) {
+			return nil, false
+		}
+	}
+
+	if !hasTaggedFields(t, tagName) {
+		return nil, false
+	}
+	return t, true
+}
+
+// hasTaggedFields reports whether the struct type has any tagged fields, including embedded ones
+func hasTaggedFields(t reflect.Type, tagName string) bool {
+	// seen guards against recursion through embedded pointers
+	seen := map[reflect.Type]bool{}
+
+	var check func(t reflect.Type) bool
+	check = func(t reflect.Type) bool {
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if promoted, ok := promotedStructType(field, tagName); ok {
+				if check(promoted) {
+					return true
+				}
+				continue
+			}
+			if _, _, ok := lookupTag(field, tagName); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return check(t)
+}
+
+// promotedStructType returns the struct type whose fields are promoted into the parent without a prefix:
+// an embedded struct, an embedded pointer to struct, or a struct field with the inline tag option.
+// Pointers are allocated when unmarshaling, which is not possible for unexported fields,
+// so unexported pointers are not promoted.
+func promotedStructType(field reflect.StructField, tagName string) (reflect.Type, bool) {
+	if !field.Anonymous {
+		_, tagOpts := parseTag(field.Tag.Get(tagName))
+		if !tagOpts.Contains(tagOptInline) || !field.IsExported() {
+			return nil, false
+		}
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		if !field.IsExported() {
+			return nil, false
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	return t, true
+}
+
+// fieldMapKey is the key of fieldMapCache
+type fieldMapKey struct {
+	typ             reflect.Type
+	tagName         string
+	nestedSeparator string
+}
+
+// fieldMapCache caches the fieldMap of each struct type.
+// fieldMaps are never modified after construction, so they can be shared safely.
+var fieldMapCache sync.Map // map[fieldMapKey]fieldMap
+
+// cachedFieldMap returns the fieldMap for t, building and caching it on first use
+func cachedFieldMap(t reflect.Type, opts *Options) fieldMap {
+	key := fieldMapKey{typ: t, tagName: opts.tagName(), nestedSeparator: opts.nestedSeparator()}
+	if fm, ok := fieldMapCache.Load(key); ok {
+		return fm.(fieldMap)
+	}
+	fm, _ := fieldMapCache.LoadOrStore(key, getFieldMap(t, opts))
+	return fm.(fieldMap)
+}
+
+// fieldByIndex returns the nested field by index.
+// It reports false if a nil pointer is encountered on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, idx := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v, true
+}
+
+// viaPointer reports whether the path to the field at index passes through a pointer
+func viaPointer(t reflect.Type, index []int) bool {
+	for _, idx := range index[:len(index)-1] {
+		t = t.Field(idx).Type
+		if t.Kind() == reflect.Ptr {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndexAlloc returns the nested field by index, allocating nil pointers on the way
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, idx := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v
+}
+
 // findTagIndex returns the index of the tag in orderedTags, or -1 if not found
 func (fm *fieldMap) findTagIndex(tag string) int {
 	for i, t := range fm.orderedTags {
@@ -221,11 +719,70 @@ func (fm *fieldMap) hasTag(tag string) bool {
 	return false
 }
 
+// foldTag returns the tag equal to name under Unicode case folding.
+// If no tag matches, name is returned unchanged. It is an error for several tags to match.
+func (fm *fieldMap) foldTag(name string) (string, error) {
+	var matches []string
+	for _, t := range fm.orderedTags {
+		if strings.EqualFold(t, name) {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return name, nil
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("header %q matches columns %s under case folding", name, strings.Join(matches, ", "))
+}
+
+// fuzzyTag returns the tag equal to name after normalizing both with normalizeHeader.
+// If no tag matches, name is returned unchanged. It is an error for several tags to match.
+func (fm *fieldMap) fuzzyTag(name string) (string, error) {
+	key := normalizeHeader(name)
+	if key == "" {
+		return name, nil
+	}
+	var matches []string
+	for _, t := range fm.orderedTags {
+		if normalizeHeader(t) == key {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return name, nil
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("header %q matches columns %s under fuzzy matching", name, strings.Join(matches, ", "))
+}
+
+// normalizeHeader lowercases s and removes everything but letters and digits
+func normalizeHeader(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
 // setField sets the value of a struct field from a string with custom options
 func setField(field reflect.Value, value string, opts *Options) error {
+	opts = opts.forType(field.Type())
+
+	// Null handles nil cells itself, with the options in use
+	if field.CanAddr() {
+		if n, ok := field.Addr().Interface().(nullCell); ok {
+			return n.unmarshalCellOptions(value, opts)
+		}
+	}
+
 	// Handle nil value
-	if value == opts.NilValue {
-		if field.Kind() == reflect.Ptr {
+	if opts.isNil(value) {
+		if field.Kind() == reflect.Ptr || field.Kind() == reflect.Map || field.Kind() == reflect.Interface {
 			field.Set(reflect.Zero(field.Type()))
 			return nil
 		}
@@ -233,16 +790,55 @@ func setField(field reflect.Value, value string, opts *Options) error {
 		return fmt.Errorf("cannot set nil to non-pointer field of type: %v", field.Type())
 	}
 
+	return setValue(field, opts.unescapeNil(value), opts)
+}
+
+// setValue sets the value of a struct field from a string that is not nil
+func setValue(field reflect.Value, value string, opts *Options) error {
+	if opts.TrimSpace {
+		value = strings.TrimSpace(value)
+	}
+
 	// Handle pointer types
 	if field.Kind() == reflect.Ptr {
-		if value == "" {
+		if value == "" && !opts.EmptyStringNotNil {
 			field.Set(reflect.Zero(field.Type()))
 			return nil
 		}
 		if field.IsNil() {
 			field.Set(reflect.New(field.Type().Elem()))
 		}
-		return setField(field.Elem(), value, opts)
+		return setValue(field.Elem(), value, opts)
+	}
+
+	// Interface fields are populated through the pointer they hold,
+	// since the concrete type to create cannot be known from the cell
+	if field.Kind() == reflect.Interface {
+		elem := field.Elem()
+		if elem.Kind() != reflect.Ptr || elem.IsNil() {
+			return fmt.Errorf("interface field of type %v must hold a non-nil pointer to unmarshal into", field.Type())
+		}
+		return setValue(elem.Elem(), value, opts)
+	}
+
+	// Enum mappings from the options take precedence over the type's own unmarshaling
+	if m, ok := opts.enumMap(field.Type()); ok {
+		v, err := parseEnum(m, field.Type(), value)
+		if err != nil {
+			return err
+		}
+		if field.CanInt() {
+			if field.OverflowInt(v) {
+				return fmt.Errorf("value %d of %q overflows %v", v, value, field.Type())
+			}
+			field.SetInt(v)
+		} else {
+			if v < 0 || field.OverflowUint(uint64(v)) {
+				return fmt.Errorf("value %d of %q overflows %v", v, value, field.Type())
+			}
+			field.SetUint(uint64(v))
+		}
+		return nil
 	}
 
 	// 1. Check for CellUnmarshaler
@@ -252,6 +848,44 @@ func setField(field reflect.Value, value string, opts *Options) error {
 		}
 	}
 
+	// time.Time follows TimeLayout and Location rather than its own UnmarshalText, which requires RFC 3339
+	if field.Type() == timeType {
+		t, err := opts.parseTime(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	// big.Float implements encoding.TextUnmarshaler, but it parses with 64 bits of precision,
+	// which loses digits of large values
+	if field.Type() == bigFloatType {
+		return setBigFloat(field.Addr().Interface().(*big.Float), value)
+	}
+
+	// url.URL and net.IPNet do not implement encoding.TextUnmarshaler
+	switch field.Type() {
+	case urlType:
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(*u))
+		return nil
+	case ipNetType:
+		if value == "" {
+			field.Set(reflect.Zero(ipNetType))
+			return nil
+		}
+		_, n, err := net.ParseCIDR(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(*n))
+		return nil
+	}
+
 	// 2. Check for encoding.TextUnmarshaler
 	if field.CanAddr() {
 		if tu, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
@@ -260,45 +894,104 @@ func setField(field reflect.Value, value string, opts *Options) error {
 	}
 
 	// 3. Built-in type conversions
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
 	switch field.Kind() {
 	case reflect.String:
 		field.SetString(value)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		i, err := strconv.ParseInt(value, 10, 64)
+		base, err := opts.parseIntBase()
+		if err != nil {
+			return err
+		}
+		cell, err := opts.parseIntCell(value, base)
 		if err != nil {
 			return err
 		}
+		i, err := strconv.ParseInt(cell, base, field.Type().Bits())
+		if err != nil {
+			return rangeError(err, value, field.Type())
+		}
 		field.SetInt(i)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		i, err := strconv.ParseUint(value, 10, 64)
+		base, err := opts.parseIntBase()
 		if err != nil {
 			return err
 		}
+		cell, err := opts.parseIntCell(value, base)
+		if err != nil {
+			return err
+		}
+		i, err := strconv.ParseUint(cell, base, field.Type().Bits())
+		if err != nil {
+			return rangeError(err, value, field.Type())
+		}
 		field.SetUint(i)
 	case reflect.Float32, reflect.Float64:
-		f, err := strconv.ParseFloat(value, 64)
+		f, err := strconv.ParseFloat(opts.stripThousands(value), field.Type().Bits())
 		if err != nil {
-			return err
+			return rangeError(err, value, field.Type())
 		}
 		field.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(value, field.Type().Bits())
+		if err != nil {
+			return rangeError(err, value, field.Type())
+		}
+		field.SetComplex(c)
 	case reflect.Bool:
-		b, err := strconv.ParseBool(value)
+		b, err := opts.parseBool(value)
 		if err != nil {
 			return err
 		}
 		field.SetBool(b)
+	case reflect.Map:
+		return setMap(field, value, opts)
 	default:
 		return fmt.Errorf("unsupported field type: %v", field.Kind())
 	}
 	return nil
 }
 
-// formatField converts a struct field to string
-func formatField(field reflect.Value, opts *Options) string {
+// rangeError describes a parse error caused by a value out of the range of the field type
+func rangeError(err error, value string, t reflect.Type) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("value %q overflows %v: %w", value, t, err)
+	}
+	return err
+}
+
+// setBigFloat parses a big.Float with a precision large enough to keep every digit of value
+func setBigFloat(f *big.Float, value string) error {
+	prec := max(64, uint(math.Ceil(float64(len(value))*math.Log2(10))))
+	_, _, err := f.SetPrec(prec).Parse(value, 0)
+	return err
+}
+
+// formatField converts a struct field to string.
+// Errors returned by CellMarshaler and encoding.TextMarshaler implementations are propagated.
+func formatField(field reflect.Value, opts *Options) (string, error) {
+	opts = opts.forType(field.Type())
+
 	// Handle pointer types
 	if field.Kind() == reflect.Ptr {
 		if field.IsNil() {
-			return opts.NilValue
+			return opts.NilValue, nil
+		}
+		return formatField(field.Elem(), opts)
+	}
+
+	// Interface fields are formatted by their dynamic value
+	if field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return opts.NilValue, nil
 		}
 		return formatField(field.Elem(), opts)
 	}
@@ -310,53 +1003,293 @@ func formatField(field reflect.Value, opts *Options) string {
 		field = newValue
 	}
 
+	// Null is converted with the options in use rather than through CellMarshaler
+	if n, ok := field.Addr().Interface().(nullCell); ok {
+		return n.marshalCellOptions(opts)
+	}
+
+	if field.Kind() == reflect.Map && field.IsNil() {
+		return opts.NilValue, nil
+	}
+
+	cell, err := formatValue(field, opts)
+	if err != nil {
+		return "", err
+	}
+	return opts.escapeNil(cell), nil
+}
+
+// formatValue converts an addressable struct field that is not nil to string
+func formatValue(field reflect.Value, opts *Options) (string, error) {
+	// Enum mappings from the options take precedence over the type's own marshaling.
+	// Values without a name are formatted as numbers.
+	if m, ok := opts.enumMap(field.Type()); ok {
+		var v int64
+		if field.CanInt() {
+			v = field.Int()
+		} else {
+			v = int64(field.Uint())
+		}
+		if name, ok := formatEnum(m, v); ok {
+			return name, nil
+		}
+	}
+
 	// 1. Check for CellMarshaler
 	if field.CanAddr() {
 		if tm, ok := field.Addr().Interface().(CellMarshaler); ok {
-			str, err := tm.MarshalCell()
-			if err == nil {
-				return str
-			}
-			// Fall through on error
+			return tm.MarshalCell()
+		}
+	}
+
+	// time.Time follows TimeLayout and Location rather than its own MarshalText
+	if field.Type() == timeType {
+		return opts.formatTime(field.Interface().(time.Time)), nil
+	}
+
+	// big.Float follows FloatFormat rather than its own MarshalText, which switches to exponent form
+	if field.Type() == bigFloatType {
+		return opts.formatBigFloat(field.Addr().Interface().(*big.Float)), nil
+	}
+
+	// url.URL and net.IPNet do not implement encoding.TextMarshaler
+	switch field.Type() {
+	case urlType:
+		return field.Addr().Interface().(*url.URL).String(), nil
+	case ipNetType:
+		n := field.Addr().Interface().(*net.IPNet)
+		if n.IP == nil {
+			return "", nil
 		}
+		return n.String(), nil
 	}
 
 	// 2. Check for encoding.TextMarshaler
 	if field.CanAddr() {
 		if tm, ok := field.Addr().Interface().(encoding.TextMarshaler); ok {
 			bytes, err := tm.MarshalText()
-			if err == nil {
-				return string(bytes)
+			if err != nil {
+				return "", err
 			}
-			// Fall through on error
+			return string(bytes), nil
 		}
 	}
 
 	// 3. Built-in type conversions
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String(), nil
+	}
+
 	switch field.Kind() {
 	case reflect.String:
-		return field.String()
+		return field.String(), nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return strconv.FormatInt(field.Int(), 10)
+		base, err := opts.formatIntBase()
+		if err != nil {
+			return "", err
+		}
+		if base != 10 {
+			return strconv.FormatInt(field.Int(), base), nil
+		}
+		return opts.groupThousands(strconv.FormatInt(field.Int(), 10)), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return strconv.FormatUint(field.Uint(), 10)
+		base, err := opts.formatIntBase()
+		if err != nil {
+			return "", err
+		}
+		if base != 10 {
+			return strconv.FormatUint(field.Uint(), base), nil
+		}
+		return opts.groupThousands(strconv.FormatUint(field.Uint(), 10)), nil
 	case reflect.Float32, reflect.Float64:
-		return strconv.FormatFloat(field.Float(), 'f', -1, 64)
+		return opts.groupThousands(opts.formatFloat(field.Float())), nil
+	case reflect.Complex64, reflect.Complex128:
+		return opts.formatComplex(field.Complex(), field.Type().Bits()), nil
 	case reflect.Bool:
-		return strconv.FormatBool(field.Bool())
+		return opts.BoolFormat.formatBool(field.Bool()), nil
+	case reflect.Map:
+		return formatMap(field, opts)
 	default:
-		return fmt.Sprintf("%v", field.Interface())
+		return fmt.Sprintf("%v", field.Interface()), nil
+	}
+}
+
+// setMap sets a map field from a cell of key/value entries, such as "a=1;b=2".
+// Keys and values are converted like fields. An empty cell sets an empty map.
+func setMap(field reflect.Value, value string, opts *Options) error {
+	m := reflect.MakeMap(field.Type())
+	if value != "" {
+		entries, err := splitMap(value, opts)
+		if err != nil {
+			return err
+		}
+		opts := opts.mapElemOptions()
+		for _, entry := range entries {
+			k, v := entry[0], entry[1]
+			key := reflect.New(field.Type().Key()).Elem()
+			if err := setField(key, k, opts); err != nil {
+				return fmt.Errorf("map key %q: %w", k, err)
+			}
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setField(elem, v, opts); err != nil {
+				return fmt.Errorf("map value for key %q: %w", k, err)
+			}
+			m.SetMapIndex(key, elem)
+		}
+	}
+	field.Set(m)
+	return nil
+}
+
+// formatMap converts a non-nil map field to a cell of key/value entries sorted by their formatted keys.
+func formatMap(field reflect.Value, opts *Options) (string, error) {
+	elemOpts := opts.mapElemOptions()
+	entries := make([][2]string, 0, field.Len())
+	iter := field.MapRange()
+	for iter.Next() {
+		k, err := formatField(iter.Key(), elemOpts)
+		if err != nil {
+			return "", err
+		}
+		v, err := formatField(iter.Value(), elemOpts)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, [2]string{k, v})
+	}
+	slices.SortFunc(entries, func(a, b [2]string) int {
+		return strings.Compare(a[0], b[0])
+	})
+
+	escape := func(s string) string { return s }
+	if opts.EscapeMapSeparators {
+		escape = strings.NewReplacer(
+			`\`, `\\`,
+			opts.mapEntrySeparator(), `\`+opts.mapEntrySeparator(),
+			opts.mapKVSeparator(), `\`+opts.mapKVSeparator(),
+		).Replace
+	}
+
+	var sb strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			sb.WriteString(opts.mapEntrySeparator())
+		}
+		sb.WriteString(escape(e[0]))
+		sb.WriteString(opts.mapKVSeparator())
+		sb.WriteString(escape(e[1]))
+	}
+	return sb.String(), nil
+}
+
+// splitMap splits a non-empty map cell into its key/value entries.
+// With EscapeMapSeparators, a backslash makes the following separator or backslash literal.
+func splitMap(value string, opts *Options) ([][2]string, error) {
+	entrySep, kvSep := opts.mapEntrySeparator(), opts.mapKVSeparator()
+
+	if !opts.EscapeMapSeparators {
+		var entries [][2]string
+		for _, entry := range strings.Split(value, entrySep) {
+			k, v, ok := strings.Cut(entry, kvSep)
+			if !ok {
+				return nil, fmt.Errorf("invalid map entry: %q", entry)
+			}
+			entries = append(entries, [2]string{k, v})
+		}
+		return entries, nil
+	}
+
+	var (
+		entries [][2]string
+		cur     strings.Builder
+		key     string
+		hasKey  bool
+	)
+	endEntry := func() error {
+		if !hasKey {
+			return fmt.Errorf("invalid map entry: %q", cur.String())
+		}
+		entries = append(entries, [2]string{key, cur.String()})
+		cur.Reset()
+		hasKey = false
+		return nil
+	}
+	for i := 0; i < len(value); {
+		rest := value[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1:
+			n := 1
+			for _, sep := range []string{entrySep, kvSep} {
+				if strings.HasPrefix(rest[1:], sep) {
+					n = len(sep)
+					break
+				}
+			}
+			cur.WriteString(rest[1 : 1+n])
+			i += 1 + n
+		case strings.HasPrefix(rest, entrySep):
+			if err := endEntry(); err != nil {
+				return nil, err
+			}
+			i += len(entrySep)
+		case !hasKey && strings.HasPrefix(rest, kvSep):
+			key = cur.String()
+			cur.Reset()
+			hasKey = true
+			i += len(kvSep)
+		default:
+			cur.WriteByte(rest[0])
+			i++
+		}
+	}
+	if err := endEntry(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// setJSONField sets the value of a struct field by decoding a JSON cell.
+// An empty or nil cell leaves the field at its zero value.
+func setJSONField(field reflect.Value, value string, opts *Options) error {
+	field.Set(reflect.Zero(field.Type()))
+	if value == "" || opts.isNil(value) {
+		return nil
 	}
+	return json.Unmarshal([]byte(opts.unescapeNil(value)), field.Addr().Interface())
+}
+
+// formatJSONField converts a struct field to a compact JSON string.
+// A nil field is converted to the nil value.
+func formatJSONField(field reflect.Value, opts *Options) (string, error) {
+	switch field.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if field.IsNil() {
+			return opts.NilValue, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(field.Interface()); err != nil {
+		return "", err
+	}
+	return opts.escapeNil(strings.TrimSuffix(buf.String(), "\n")), nil
 }
 
 // row represents a single row of table data processor
 type row struct {
-	header []string
-	fields map[string]fieldInfo
-	opts   *Options
+	header      []string
+	columns     []string // Tags corresponding to each header column
+	unmapped    []string // Header columns that do not map to any field
+	fields      map[string]fieldInfo
+	orderedTags []string
+	opts        *Options
+	layoutOpts  map[string]*Options // Options of the fields with a layout tag option
 }
 
-// newRow creates a Row processor with given header for type T
+// newRow creates a Row processor with given header for type T.
+// If header is nil, Options.Header is used, falling back to all columns in declaration order.
 func newRow(structType reflect.Type, header []string, opts *Options) (*row, error) {
 	if opts == nil {
 		opts = DefaultOptions()
@@ -367,23 +1300,141 @@ func newRow(structType reflect.Type, header []string, opts *Options) (*row, erro
 	}
 
 	// Get field mapping including embedded fields
-	fm := getFieldMap(structType)
+	fm := cachedFieldMap(structType, opts)
+	if len(fm.unexported) > 0 {
+		return nil, fmt.Errorf("unexported field %s has a table tag; export it or remove the tag", fm.unexported[0])
+	}
+	if fm.err != nil {
+		return nil, fm.err
+	}
+
+	// Marshal the columns listed in the options, if any
+	fromOpts := header == nil && opts.Header != nil
+	if fromOpts {
+		header = slices.Clone(opts.Header)
+	}
 
+	var columns, unmapped []string
 	if header == nil {
-		header = fm.orderedTags
+		// Copy so that callers cannot modify the cached tags
+		columns = slices.Clone(fm.orderedTags)
+		header = make([]string, len(columns))
+		for i, tag := range columns {
+			header[i] = opts.outputHeader(tag)
+		}
+	} else {
+		columns = make([]string, len(header))
+		for i, h := range header {
+			columns[i] = opts.inputColumn(h)
+			// Exact matches take precedence over case-insensitive ones
+			if _, ok := fm.fields[columns[i]]; !ok && opts.HeaderCaseFold {
+				col, err := fm.foldTag(columns[i])
+				if err != nil {
+					return nil, err
+				}
+				columns[i] = col
+			}
+			if _, ok := fm.fields[columns[i]]; !ok && opts.FuzzyHeaderMatch {
+				col, err := fm.fuzzyTag(columns[i])
+				if err != nil {
+					return nil, err
+				}
+				columns[i] = col
+			}
+			if j := slices.Index(columns[:i], columns[i]); j >= 0 {
+				// Unmapped columns are ignored, so only headers mapping to the same field collide
+				_, mapped := fm.fields[columns[i]]
+				if mapped && (opts.HeaderTransform != nil || opts.HeaderCaseFold || opts.FuzzyHeaderMatch) {
+					return nil, fmt.Errorf("headers %q and %q both map to column %s", header[j], h, columns[i])
+				}
+				if opts.ErrorOnDuplicateHeader {
+					return nil, fmt.Errorf("duplicate column in header: %s", h)
+				}
+			}
+			if _, ok := fm.fields[columns[i]]; !ok {
+				if fromOpts {
+					return nil, fmt.Errorf("unknown column in Options.Header: %s", h)
+				}
+				unmapped = append(unmapped, h)
+			}
+		}
+	}
+
+	// Fields with a layout tag option use a copy of the options with their own TimeLayout
+	var layoutOpts map[string]*Options
+	for tag, info := range fm.fields {
+		if info.layout == "" {
+			continue
+		}
+		if layoutOpts == nil {
+			layoutOpts = make(map[string]*Options)
+		}
+		c := *opts
+		c.TimeLayout = info.layout
+		layoutOpts[tag] = &c
 	}
 
 	return &row{
-		header: header,
-		fields: fm.fields,
-		opts:   opts,
+		header:      header,
+		columns:     columns,
+		unmapped:    unmapped,
+		fields:      fm.fields,
+		orderedTags: fm.orderedTags,
+		opts:        opts,
+		layoutOpts:  layoutOpts,
 	}, nil
 }
 
+// fieldOptions returns the options used to convert the cells of a field
+func (r *row) fieldOptions(info fieldInfo) *Options {
+	if opts, ok := r.layoutOpts[info.tag]; ok {
+		return opts
+	}
+	return r.opts
+}
+
+// checkMissingColumns returns an error listing the columns of the struct that are missing from the header, if any
+func (r *row) checkMissingColumns() error {
+	if missing := r.missingColumns(); len(missing) > 0 {
+		return fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// missingColumns returns the tags of the struct that are not present in the header, in declaration order
+func (r *row) missingColumns() []string {
+	var missing []string
+	for _, tag := range r.orderedTags {
+		if !slices.Contains(r.columns, tag) {
+			missing = append(missing, tag)
+		}
+	}
+	return missing
+}
+
+// checkRequiredColumns returns an error listing the required columns of the struct
+// that are missing from the header, if any
+func (r *row) checkRequiredColumns() error {
+	var missing []string
+	for _, tag := range r.orderedTags {
+		if r.fields[tag].required && !slices.Contains(r.columns, tag) {
+			missing = append(missing, tag)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required columns: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 // UnmarshalRow converts a single row of data into a struct
-func (r *row) unmarshalRow(data []string, v any) error {
+func (r *row) unmarshalRow(index int, data []string, v any) error {
 	if len(data) != len(r.header) {
-		return fmt.Errorf("inconsistent data length")
+		if !r.opts.AllowRaggedRows {
+			return fmt.Errorf("inconsistent data length")
+		}
+		// Ignore extra cells beyond the header
+		data = data[:min(len(data), len(r.header))]
 	}
 
 	rv := reflect.ValueOf(v)
@@ -397,26 +1448,111 @@ func (r *row) unmarshalRow(data []string, v any) error {
 	}
 
 	// Fill the struct fields
-	for i, col := range data {
-		if info, ok := r.fields[r.header[i]]; ok {
-			// Navigate to the field through the embedded structs
-			field := structVal
-			for _, idx := range info.index {
-				field = field.Field(idx)
-			}
-			if err := setField(field, col, r.opts); err != nil {
+	for i, column := range r.columns {
+		info, ok := r.fields[column]
+		if !ok {
+			continue
+		}
+		var col string
+		if i < len(data) {
+			col = data[i]
+		} else if _, ok := r.defaultValue(info); !ok && !info.required {
+			// Cells missing from a ragged row are empty, which only matters
+			// for fields with a default value or required fields
+			continue
+		}
+
+		if err := r.setCell(structVal, info, col); err != nil {
+			if r.opts.FieldErrorHandler != nil {
+				err = r.opts.FieldErrorHandler(index, r.header[i], col, err)
+			}
+			if err != nil {
 				return fmt.Errorf("setting field %s: %v", r.header[i], err)
 			}
+			// Discard anything set before the failure
+			field := fieldByIndexAlloc(structVal, info.index)
+			field.Set(reflect.Zero(field.Type()))
 		}
 	}
 
 	return nil
 }
 
+// defaultValue returns the default value of a field, from DefaultValues or else from its tag
+func (r *row) defaultValue(info fieldInfo) (string, bool) {
+	if def, ok := r.opts.DefaultValues[info.tag]; ok {
+		return def, true
+	}
+	return info.defaultValue, info.hasDefault
+}
+
+// setCell sets the field described by info in structVal from a cell,
+// applying the column transform and default value of the field
+func (r *row) setCell(structVal reflect.Value, info fieldInfo, col string) error {
+	if transform, ok := r.opts.ColumnTransforms[info.tag]; ok {
+		col = transform(col)
+	}
+
+	fieldOpts := r.opts.forType(structVal.Type().FieldByIndex(info.index).Type)
+
+	// Fall back to the default value for empty or nil cells
+	if def, ok := r.defaultValue(info); ok && (col == "" || fieldOpts.isNil(col)) {
+		col = def
+	}
+
+	if info.required && (isBlank(col, r.opts) || fieldOpts.isNil(col)) {
+		return fmt.Errorf("required value is empty")
+	}
+
+	// A nil pointer to a nested or embedded struct marshals all of its columns as nil,
+	// so nil cells under a pointer leave the field unset instead of allocating the pointer
+	if info.viaPointer && fieldOpts.isNil(col) {
+		return nil
+	}
+
+	// Navigate to the field through the embedded and nested structs
+	field := fieldByIndexAlloc(structVal, info.index)
+
+	if info.json {
+		return setJSONField(field, col, fieldOpts)
+	}
+	return setField(field, col, r.fieldOptions(info))
+}
+
+// isBlank reports whether a cell is empty, after trimming white space if TrimSpace is set
+func isBlank(col string, opts *Options) bool {
+	if opts.TrimSpace {
+		col = strings.TrimSpace(col)
+	}
+	return col == ""
+}
+
+// isZero reports whether the fields of all columns of a struct are zero.
+// Fields under a nil embedded or nested pointer count as zero.
+func (r *row) isZero(structVal reflect.Value) bool {
+	for _, tag := range r.columns {
+		info, ok := r.fields[tag]
+		if !ok {
+			continue
+		}
+		if field, ok := fieldByIndex(structVal, info.index); ok && !field.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
 // MarshalRow converts a struct into a single row of data
 func (r *row) marshalRow(v any) ([]string, error) {
+	return r.marshalRowTo(nil, v)
+}
+
+// marshalRowTo converts a struct into a single row of data, writing into dst.
+// dst is grown if it is too short.
+func (r *row) marshalRowTo(dst []string, v any) ([]string, error) {
+	// Dereference pointers until reaching the struct
 	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
 		if rv.IsNil() {
 			return nil, fmt.Errorf("v must not be nil")
 		}
@@ -424,18 +1560,38 @@ func (r *row) marshalRow(v any) ([]string, error) {
 	}
 
 	if rv.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("v must be a struct or pointer to struct")
+		return nil, fmt.Errorf("v must be a struct or pointer to struct, got %v", rv.Kind())
 	}
 
-	row := make([]string, len(r.header))
-	for i, tag := range r.header {
+	row := dst
+	if cap(row) < len(r.columns) {
+		row = make([]string, len(r.columns))
+	} else {
+		row = row[:len(r.columns)]
+	}
+	for i, tag := range r.columns {
+		row[i] = ""
 		if info, ok := r.fields[tag]; ok {
-			// Navigate to the field through the embedded structs
-			field := rv
-			for _, idx := range info.index {
-				field = field.Field(idx)
+			// Navigate to the field through the embedded and nested structs
+			field, ok := fieldByIndex(rv, info.index)
+			if !ok {
+				// A nil nested struct is treated as all of its fields being nil
+				row[i] = r.opts.NilValue
+				continue
 			}
-			row[i] = formatField(field, r.opts)
+			if info.json {
+				cell, err := formatJSONField(field, r.opts.forType(field.Type()))
+				if err != nil {
+					return nil, fmt.Errorf("formatting field %s: %w", tag, err)
+				}
+				row[i] = r.opts.transformOutput(info.tag, cell)
+				continue
+			}
+			cell, err := formatField(field, r.fieldOptions(info))
+			if err != nil {
+				return nil, fmt.Errorf("formatting field %s: %w", tag, err)
+			}
+			row[i] = r.opts.transformOutput(info.tag, cell)
 		}
 	}
 
@@ -444,29 +1600,114 @@ func (r *row) marshalRow(v any) ([]string, error) {
 
 // RowHandler provides a type-safe way to process table data row by row
 type RowHandler[T any] struct {
-	row *row
+	row    *row
+	strict bool // Whether every column of the struct must be present in the header
+	rows   int  // Number of rows unmarshaled, the index passed to FieldErrorHandler
 }
 
-// NewRowHandler creates a new RowHandler for the given type and header
+// NewRowHandler creates a new RowHandler for the given type and header.
+// It is an error for the header to leave out a column with the required tag option.
 func NewRowHandler[T any](header []string, opts *Options) (*RowHandler[T], error) {
 	var zero T
 	r, err := newRow(reflect.TypeOf(zero), header, opts)
 	if err != nil {
 		return nil, err
 	}
+	// Without a header, the handler marshals the columns of Options.Header or of the struct
+	if header != nil {
+		if err := r.checkRequiredColumns(); err != nil {
+			return nil, err
+		}
+	}
 	return &RowHandler[T]{row: r}, nil
 }
 
+// NewRowHandlerStrict creates a new RowHandler for the given type and header,
+// returning an error if any column of the struct is missing from the header.
+func NewRowHandlerStrict[T any](header []string, opts *Options) (*RowHandler[T], error) {
+	h, err := NewRowHandler[T](header, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.row.checkMissingColumns(); err != nil {
+		return nil, err
+	}
+	h.strict = true
+	return h, nil
+}
+
+// Reset switches the handler to a new header, keeping its type and options.
+// The field mapping of the type is cached, so this is cheaper than creating a new handler,
+// which helps when processing many small inputs.
+// For a handler created with NewRowHandlerStrict, it is an error for any column of the struct
+// to be missing from the new header, or for the new header to have columns that map to no field.
+// On error, the handler keeps its previous header.
+func (h *RowHandler[T]) Reset(header []string) error {
+	var zero T
+	r, err := newRow(reflect.TypeOf(zero), header, h.row.opts)
+	if err != nil {
+		return err
+	}
+	if err := r.checkRequiredColumns(); err != nil {
+		return err
+	}
+	if h.strict {
+		if err := r.checkMissingColumns(); err != nil {
+			return err
+		}
+		if len(r.unmapped) > 0 {
+			return fmt.Errorf("unknown columns: %s", strings.Join(r.unmapped, ", "))
+		}
+	}
+	h.row = r
+	h.rows = 0
+	return nil
+}
+
+// Header returns a copy of the header of the handler.
+// For a handler created with a nil header, it is the header that MarshalRow produces rows for.
+func (h *RowHandler[T]) Header() []string {
+	return slices.Clone(h.row.header)
+}
+
+// UnmappedColumns returns the header columns that do not map to any field of T, in header order.
+// These columns are ignored when unmarshaling.
+func (h *RowHandler[T]) UnmappedColumns() []string {
+	return slices.Clone(h.row.unmapped)
+}
+
 // UnmarshalRow converts a single row of data into a struct of type T
 func (h *RowHandler[T]) UnmarshalRow(data []string) (*T, error) {
 	var result T
-	if err := h.row.unmarshalRow(data, &result); err != nil {
+	if err := h.UnmarshalRowInto(data, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
+// UnmarshalRowInto converts a single row of data into an existing struct of type T.
+// Fields without a column in the header keep their values, which allows
+// pre-populating interface fields with the pointer to unmarshal into.
+func (h *RowHandler[T]) UnmarshalRowInto(data []string, v *T) error {
+	h.rows++
+	return h.row.unmarshalRow(h.rows-1, data, v)
+}
+
+// OmitRow reports whether v is left out under Options.OmitZeroRows,
+// that is, whether the option is set and the fields of all columns of v are zero.
+// MarshalRow does not check it, so callers writing one row at a time should.
+func (h *RowHandler[T]) OmitRow(v *T) bool {
+	return h.row.opts.OmitZeroRows && h.row.isZero(reflect.ValueOf(v).Elem())
+}
+
 // MarshalRow converts a struct of type T into a single row of data
 func (h *RowHandler[T]) MarshalRow(v *T) ([]string, error) {
-	return h.row.marshalRow(v)
+	return h.MarshalRowTo(nil, v)
+}
+
+// MarshalRowTo converts a struct of type T into a single row of data, writing into dst.
+// dst is grown if it is too short, so the returned slice should be used.
+// Reusing the returned slice across calls avoids allocating a new row each time.
+func (h *RowHandler[T]) MarshalRowTo(dst []string, v *T) ([]string, error) {
+	return h.row.marshalRowTo(dst, v)
 }
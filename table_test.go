@@ -1,7 +1,13 @@
 package tablemap_test
 
 import (
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -43,6 +49,22 @@ func (t *TimeWrapper) UnmarshalText(text []byte) error {
 	return nil
 }
 
+var errMarshal = errors.New("marshal failed")
+
+// failingCell implements CellMarshaler and always fails
+type failingCell struct{}
+
+func (failingCell) MarshalCell() (string, error) {
+	return "", errMarshal
+}
+
+// failingText implements encoding.TextMarshaler and always fails
+type failingText struct{}
+
+func (failingText) MarshalText() ([]byte, error) {
+	return nil, errMarshal
+}
+
 type TestStruct struct {
 	String    string      `table:"string"`
 	Int       int         `table:"int"`
@@ -524,6 +546,236 @@ func TestUnmarshal_embedded(t *testing.T) {
 	}
 }
 
+type unexportedBase struct {
+	ID      int       `table:"id"`
+	Created time.Time `table:"created"`
+}
+
+type unexportedMiddle struct {
+	unexportedBase
+	Owner Customer `table:"owner"`
+}
+
+type PersonWithUnexportedBase struct {
+	unexportedMiddle
+	Name string `table:"name"`
+}
+
+type PersonWithAddressPtr struct {
+	Name string `table:"name"`
+	*EmbeddedAddress
+}
+
+type selfEmbedded struct {
+	Name string `table:"name"`
+	*selfEmbedded
+}
+
+type SelfEmbedded struct {
+	Name string `table:"name"`
+	*SelfEmbedded
+}
+
+func TestMarshal_embeddedPointer(t *testing.T) {
+	input := []PersonWithAddressPtr{
+		{Name: "John", EmbeddedAddress: &EmbeddedAddress{Street: "123 Main St", City: "Springfield"}},
+		{Name: "Jane"},
+	}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "street", "city"}, header)
+	assert.Equal(t, [][]string{
+		{"John", "123 Main St", "Springfield"},
+		{"Jane", "\\N", "\\N"},
+	}, data)
+
+	// The embedded pointer is allocated when unmarshaling, unless all of its cells are nil
+	var result []PersonWithAddressPtr
+	err = tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+
+	t.Run("nil embedded pointer with numeric fields", func(t *testing.T) {
+		type Base struct {
+			Zip int     `table:"zip"`
+			Lat float64 `table:"lat"`
+		}
+		type Record struct {
+			Name string `table:"name"`
+			*Base
+		}
+		input := []Record{{Name: "a"}, {Name: "b", Base: &Base{Zip: 123}}}
+
+		header, data, err := tablemap.Marshal(input)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"a", "\\N", "\\N"}, {"b", "123", "0"}}, data)
+
+		var result []Record
+		err = tablemap.Unmarshal(header, data, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("unexported embedded pointer is skipped", func(t *testing.T) {
+		header, _, err := tablemap.Marshal([]selfEmbedded{{Name: "a"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name"}, header)
+	})
+
+	t.Run("recursive embedded pointer", func(t *testing.T) {
+		header, data, err := tablemap.Marshal([]SelfEmbedded{{Name: "a"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name"}, header)
+		assert.Equal(t, [][]string{{"a"}}, data)
+	})
+}
+
+func TestMarshal_inline(t *testing.T) {
+	type Audit struct {
+		CreatedBy string `table:"created_by"`
+		Name      string `table:"name"`
+	}
+
+	type Record struct {
+		Name   string `table:"name"`
+		Audit  Audit  `table:",inline"`
+		Update *Audit `table:",inline"`
+	}
+
+	input := []Record{
+		{Name: "a", Audit: Audit{CreatedBy: "alice", Name: "ignored"}},
+	}
+
+	// Inline fields follow the conflict rules of embedding, so "name" comes from Record
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "created_by"}, header)
+	assert.Equal(t, [][]string{{"a", "alice"}}, data)
+
+	var result []Record
+	err = tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{Name: "a", Audit: Audit{CreatedBy: "alice"}}}, result)
+
+	t.Run("inline pointer", func(t *testing.T) {
+		type Wrapper struct {
+			ID    int    `table:"id"`
+			Audit *Audit `table:",inline"`
+		}
+
+		header, data, err := tablemap.Marshal([]Wrapper{
+			{ID: 1, Audit: &Audit{CreatedBy: "bob", Name: "x"}},
+			{ID: 2},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"id", "created_by", "name"}, header)
+		assert.Equal(t, [][]string{{"1", "bob", "x"}, {"2", "\\N", "\\N"}}, data)
+
+		var result []Wrapper
+		err = tablemap.Unmarshal(header, data, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, []Wrapper{{ID: 1, Audit: &Audit{CreatedBy: "bob", Name: "x"}}, {ID: 2}}, result)
+	})
+
+	t.Run("inline non-struct is ignored", func(t *testing.T) {
+		type Wrapper struct {
+			ID   int    `table:"id"`
+			Note string `table:",inline"`
+		}
+
+		header, _, err := tablemap.Marshal([]Wrapper{{ID: 1}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"id"}, header)
+	})
+}
+
+func TestMarshal_unexportedEmbedded(t *testing.T) {
+	input := []PersonWithUnexportedBase{
+		{
+			unexportedMiddle: unexportedMiddle{
+				unexportedBase: unexportedBase{
+					ID:      1,
+					Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+				Owner: Customer{Name: "Jane", Email: "jane@example.com"},
+			},
+			Name: "John",
+		},
+	}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id", "created", "owner.name", "owner.email", "name"}, header)
+	assert.Equal(t, [][]string{
+		{"1", "2024-01-01T00:00:00Z", "Jane", "jane@example.com", "John"},
+	}, data)
+
+	// Round trip
+	var result []PersonWithUnexportedBase
+	err = tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+
+	// Row by row
+	handler, err := tablemap.NewRowHandler[PersonWithUnexportedBase](header, nil)
+	assert.NoError(t, err)
+	decoded, err := handler.UnmarshalRow(data[0])
+	assert.NoError(t, err)
+	assert.Equal(t, input[0], *decoded)
+}
+
+// withUnexportedTag has a tagged field that cannot be set through reflection
+type withUnexportedTag struct {
+	Name string `table:"name"`
+	age  int    `table:"age"`
+}
+
+func TestMarshal_unexportedTaggedField(t *testing.T) {
+	const wantErr = "unexported field tablemap_test.withUnexportedTag.age has a table tag; export it or remove the tag"
+
+	_, _, err := tablemap.Marshal([]withUnexportedTag{{Name: "John", age: 30}})
+	assert.EqualError(t, err, wantErr)
+
+	var result []withUnexportedTag
+	err = tablemap.Unmarshal([]string{"name", "age"}, [][]string{{"John", "30"}}, &result)
+	assert.EqualError(t, err, wantErr)
+
+	_, err = tablemap.NewRowHandler[withUnexportedTag](nil, nil)
+	assert.EqualError(t, err, wantErr)
+
+	t.Run("nested", func(t *testing.T) {
+		type Record struct {
+			Inner withUnexportedTag `table:"inner"`
+		}
+		_, _, err := tablemap.Marshal([]Record{{}})
+		assert.EqualError(t, err, wantErr)
+	})
+}
+
+func TestMarshal_ignoreTag(t *testing.T) {
+	type Record struct {
+		Name    string `table:"name"`
+		Ignored string `table:"-"`
+		Dash    string `table:"-,"`
+		NoTag   string
+	}
+
+	input := []Record{
+		{Name: "John", Ignored: "ignored", Dash: "dash", NoTag: "no tag"},
+	}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "-"}, header)
+	assert.Equal(t, [][]string{{"John", "dash"}}, data)
+
+	var result []Record
+	err = tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{Name: "John", Dash: "dash"}}, result)
+}
+
 func TestMarshal_headerOrder(t *testing.T) {
 	type Inner struct {
 		B string `table:"b"`
@@ -609,8 +861,8 @@ func TestMarshal_headerOrder(t *testing.T) {
 		{
 			name:           "empty struct slice",
 			input:          []Outer{},
-			expectedHeader: nil,
-			expectedData:   nil,
+			expectedHeader: []string{"a", "b", "c", "d", "e"},
+			expectedData:   [][]string{},
 		},
 		{
 			name: "multiple level embedding",
@@ -703,6 +955,205 @@ func TestRowHandler(t *testing.T) {
 	}
 }
 
+func TestRowHandler_MarshalRowTo(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	handler, err := tablemap.NewRowHandler[Person]([]string{"name", "unknown", "age"}, nil)
+	assert.NoError(t, err)
+
+	t.Run("nil dst", func(t *testing.T) {
+		row, err := handler.MarshalRowTo(nil, &Person{Name: "Alice", Age: 23})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Alice", "", "23"}, row)
+	})
+
+	t.Run("short dst is grown", func(t *testing.T) {
+		dst := make([]string, 1)
+		row, err := handler.MarshalRowTo(dst, &Person{Name: "Alice", Age: 23})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Alice", "", "23"}, row)
+	})
+
+	t.Run("dst is reused", func(t *testing.T) {
+		dst := []string{"stale", "stale", "stale", "stale"}
+		row, err := handler.MarshalRowTo(dst, &Person{Name: "Bob", Age: 25})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Bob", "", "25"}, row)
+		assert.Same(t, &dst[0], &row[0])
+	})
+}
+
+func TestNewRowHandlerStrict(t *testing.T) {
+	type Person struct {
+		Name   string  `table:"name"`
+		Age    int     `table:"age"`
+		Height float64 `table:"height"`
+	}
+
+	tests := []struct {
+		name    string
+		header  []string
+		wantErr string
+	}{
+		{
+			name:   "all columns present",
+			header: []string{"name", "age", "height"},
+		},
+		{
+			name:   "different order and extra column",
+			header: []string{"height", "extra", "name", "age"},
+		},
+		{
+			name:    "missing column",
+			header:  []string{"name", "height"},
+			wantErr: "missing columns: age",
+		},
+		{
+			name:    "multiple missing columns",
+			header:  []string{"nmae"},
+			wantErr: "missing columns: name, age, height",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := tablemap.NewRowHandlerStrict[Person](tt.header, nil)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, handler)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, handler)
+		})
+	}
+}
+
+func TestRowHandler_Reset(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	t.Run("new header", func(t *testing.T) {
+		handler, err := tablemap.NewRowHandler[Person]([]string{"name", "age"}, nil)
+		assert.NoError(t, err)
+
+		assert.NoError(t, handler.Reset([]string{"age", "extra", "name"}))
+		assert.Equal(t, []string{"age", "extra", "name"}, handler.Header())
+		assert.Equal(t, []string{"extra"}, handler.UnmappedColumns())
+
+		result, err := handler.UnmarshalRow([]string{"30", "x", "John"})
+		assert.NoError(t, err)
+		assert.Equal(t, &Person{Name: "John", Age: 30}, result)
+	})
+
+	t.Run("keeps options", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithHeaderAliases(map[string]string{"full_name": "name"})
+		handler, err := tablemap.NewRowHandler[Person]([]string{"name"}, opts)
+		assert.NoError(t, err)
+
+		assert.NoError(t, handler.Reset([]string{"full_name"}))
+		result, err := handler.UnmarshalRow([]string{"John"})
+		assert.NoError(t, err)
+		assert.Equal(t, &Person{Name: "John"}, result)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		handler, err := tablemap.NewRowHandlerStrict[Person]([]string{"name", "age"}, nil)
+		assert.NoError(t, err)
+
+		assert.NoError(t, handler.Reset([]string{"age", "name"}))
+		assert.EqualError(t, handler.Reset([]string{"name", "extra"}), "missing columns: age")
+		assert.EqualError(t, handler.Reset([]string{"name", "age", "extra", "note"}), "unknown columns: extra, note")
+
+		// The handler keeps the previous header
+		assert.Equal(t, []string{"age", "name"}, handler.Header())
+	})
+
+	t.Run("duplicate header", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithErrorOnDuplicateHeader(true)
+		handler, err := tablemap.NewRowHandler[Person]([]string{"name"}, opts)
+		assert.NoError(t, err)
+		assert.Error(t, handler.Reset([]string{"name", "name"}))
+	})
+}
+
+func TestRowHandler_Header(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	t.Run("nil header", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithOutputAliases(map[string]string{"name": "Name"})
+		handler, err := tablemap.NewRowHandler[Person](nil, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "age"}, handler.Header())
+	})
+
+	t.Run("given header", func(t *testing.T) {
+		handler, err := tablemap.NewRowHandler[Person]([]string{"age", "name", "extra"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"age", "name", "extra"}, handler.Header())
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		handler, err := tablemap.NewRowHandler[Person](nil, nil)
+		assert.NoError(t, err)
+		header := handler.Header()
+		header[0] = "modified"
+		assert.Equal(t, []string{"name", "age"}, handler.Header())
+	})
+}
+
+func TestRowHandler_UnmappedColumns(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	tests := []struct {
+		name     string
+		header   []string
+		opts     *tablemap.Options
+		expected []string
+	}{
+		{
+			name:     "all columns mapped",
+			header:   []string{"age", "name"},
+			expected: nil,
+		},
+		{
+			name:     "extra columns",
+			header:   []string{"id", "name", "age", "memo"},
+			expected: []string{"id", "memo"},
+		},
+		{
+			name:     "header aliases are mapped",
+			header:   []string{"full_name", "age"},
+			opts:     tablemap.DefaultOptions().WithHeaderAliases(map[string]string{"full_name": "name"}),
+			expected: nil,
+		},
+		{
+			name:     "nil header",
+			header:   nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := tablemap.NewRowHandler[Person](tt.header, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, handler.UnmappedColumns())
+		})
+	}
+}
+
 func TestMarshalUnmarshalCycle(t *testing.T) {
 	intVal := 42
 	testData := []TestStruct{
@@ -760,3 +1211,3312 @@ func TestUnmarshalMarshalCycle(t *testing.T) {
 	assert.Equal(t, header, headerOut)
 	assert.Equal(t, data, dataOut)
 }
+
+func TestMarshalOrdered(t *testing.T) {
+	type Record struct {
+		Name  string `table:"name"`
+		Age   int    `table:"age"`
+		Email string `table:"email"`
+	}
+
+	input := []Record{
+		{Name: "Alice", Age: 23, Email: "alice@example.com"},
+		{Name: "Bob", Age: 25, Email: "bob@example.com"},
+	}
+
+	tests := []struct {
+		name           string
+		header         []string
+		opts           *tablemap.Options
+		expectedHeader []string
+		expectedData   [][]string
+		wantErr        string
+	}{
+		{
+			name:           "permutation",
+			header:         []string{"email", "name", "age"},
+			expectedHeader: []string{"email", "name", "age"},
+			expectedData: [][]string{
+				{"alice@example.com", "Alice", "23"},
+				{"bob@example.com", "Bob", "25"},
+			},
+		},
+		{
+			name:           "subset",
+			header:         []string{"age", "name"},
+			expectedHeader: []string{"age", "name"},
+			expectedData: [][]string{
+				{"23", "Alice"},
+				{"25", "Bob"},
+			},
+		},
+		{
+			name:           "output aliases",
+			header:         []string{"Mail", "name"},
+			opts:           tablemap.DefaultOptions().WithOutputAliases(map[string]string{"email": "Mail"}),
+			expectedHeader: []string{"Mail", "name"},
+			expectedData: [][]string{
+				{"alice@example.com", "Alice"},
+				{"bob@example.com", "Bob"},
+			},
+		},
+		{
+			name:    "unknown column",
+			header:  []string{"name", "phone"},
+			wantErr: "unknown column: phone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, data, err := tablemap.MarshalOrdered(input, tt.header, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedHeader, header)
+			assert.Equal(t, tt.expectedData, data)
+		})
+	}
+}
+
+func TestMarshal_marshalerError(t *testing.T) {
+	t.Run("CellMarshaler", func(t *testing.T) {
+		type Record struct {
+			Name string      `table:"name"`
+			Cell failingCell `table:"cell"`
+		}
+
+		_, _, err := tablemap.Marshal([]Record{{Name: "Alice"}})
+		assert.EqualError(t, err, "formatting field cell: marshal failed")
+		assert.ErrorIs(t, err, errMarshal)
+	})
+
+	t.Run("TextMarshaler", func(t *testing.T) {
+		type Record struct {
+			Text *failingText `table:"text"`
+		}
+
+		_, _, err := tablemap.Marshal([]Record{{Text: &failingText{}}})
+		assert.EqualError(t, err, "formatting field text: marshal failed")
+		assert.ErrorIs(t, err, errMarshal)
+
+		// A nil pointer is not marshaled
+		_, data, err := tablemap.Marshal([]Record{{}})
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"\\N"}}, data)
+	})
+
+	t.Run("RowHandler", func(t *testing.T) {
+		type Record struct {
+			Cell failingCell `table:"cell"`
+		}
+
+		handler, err := tablemap.NewRowHandler[Record](nil, nil)
+		assert.NoError(t, err)
+		_, err = handler.MarshalRow(&Record{})
+		assert.ErrorIs(t, err, errMarshal)
+	})
+}
+
+func TestMarshalWithOptions_header(t *testing.T) {
+	type Record struct {
+		Name  string `table:"name"`
+		Age   int    `table:"age"`
+		Email string `table:"email"`
+	}
+
+	input := []Record{{Name: "Alice", Age: 23, Email: "alice@example.com"}}
+
+	t.Run("reorder and project", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithHeader([]string{"email", "name"})
+		header, data, err := tablemap.MarshalWithOptions(input, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"email", "name"}, header)
+		assert.Equal(t, [][]string{{"alice@example.com", "Alice"}}, data)
+
+		// The same options read the data back
+		var result []Record
+		err = tablemap.UnmarshalWithOptions(nil, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{{Name: "Alice", Email: "alice@example.com"}}, result)
+	})
+
+	t.Run("header in the data takes precedence", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithHeader([]string{"email", "name"})
+		var result []Record
+		err := tablemap.UnmarshalWithOptions([]string{"name", "email"}, [][]string{{"Alice", "alice@example.com"}}, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{{Name: "Alice", Email: "alice@example.com"}}, result)
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithHeader([]string{"name", "phone"})
+		_, _, err := tablemap.MarshalWithOptions(input, opts)
+		assert.EqualError(t, err, "unknown column in Options.Header: phone")
+	})
+
+	t.Run("row handler", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithHeader([]string{"age"})
+		handler, err := tablemap.NewRowHandler[Record](nil, opts)
+		assert.NoError(t, err)
+		row, err := handler.MarshalRow(&input[0])
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"23"}, row)
+	})
+}
+
+func TestMarshal_interfaceElements(t *testing.T) {
+	type A struct {
+		Name string `table:"name"`
+	}
+	type B struct {
+		ID int `table:"id"`
+	}
+
+	_, _, err := tablemap.Marshal([]any{A{Name: "a"}, B{ID: 1}})
+	assert.EqualError(t, err, "slice elements must be structs, got interface type interface {}; use a slice of a concrete struct type")
+
+	// Even a slice holding a single concrete type is rejected, since the header comes from the element type
+	_, _, err = tablemap.Marshal([]any{A{Name: "a"}})
+	assert.Error(t, err)
+}
+
+func TestMarshal_headerNotShared(t *testing.T) {
+	type Record struct {
+		A string `table:"a"`
+		B string `table:"b"`
+	}
+
+	header, _, err := tablemap.Marshal([]Record{{A: "a1", B: "b1"}})
+	assert.NoError(t, err)
+	header[0] = "modified"
+
+	header, _, err = tablemap.Marshal([]Record{{A: "a1", B: "b1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, header)
+}
+
+func TestMarshalWithOptions_boolFormat(t *testing.T) {
+	type Record struct {
+		Active  bool  `table:"active"`
+		Deleted *bool `table:"deleted"`
+	}
+
+	trueVal := true
+	falseVal := false
+
+	tests := []struct {
+		name     string
+		input    []Record
+		options  *tablemap.Options
+		expected [][]string
+	}{
+		{
+			name: "default format",
+			input: []Record{
+				{Active: true, Deleted: &falseVal},
+				{Active: false, Deleted: &trueVal},
+			},
+			options: nil,
+			expected: [][]string{
+				{"true", "false"},
+				{"false", "true"},
+			},
+		},
+		{
+			name: "numeric format",
+			input: []Record{
+				{Active: true, Deleted: &falseVal},
+				{Active: false, Deleted: &trueVal},
+			},
+			options: &tablemap.Options{BoolFormat: tablemap.BoolFormat{True: "1", False: "0"}},
+			expected: [][]string{
+				{"1", "0"},
+				{"0", "1"},
+			},
+		},
+		{
+			name: "zero value format",
+			input: []Record{
+				{Active: true, Deleted: &falseVal},
+			},
+			options: &tablemap.Options{},
+			expected: [][]string{
+				{"true", "false"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, data, err := tablemap.MarshalWithOptions(tt.input, tt.options)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+
+			// Round trip
+			var result []Record
+			err = tablemap.UnmarshalWithOptions([]string{"active", "deleted"}, data, &result, tt.options)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.input, result)
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_boolFormat(t *testing.T) {
+	type Record struct {
+		Active bool `table:"active"`
+	}
+
+	opts := &tablemap.Options{BoolFormat: tablemap.BoolFormat{True: "yes", False: "no"}}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected bool
+		wantErr  bool
+	}{
+		{name: "configured true", value: "yes", expected: true},
+		{name: "configured false", value: "no", expected: false},
+		{name: "standard true", value: "true", expected: true},
+		{name: "standard false", value: "0", expected: false},
+		{name: "invalid", value: "maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions([]string{"active"}, [][]string{{tt.value}}, &result, opts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result[0].Active)
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_boolTokens(t *testing.T) {
+	type Record struct {
+		Active bool `table:"active"`
+	}
+
+	opts := tablemap.DefaultOptions().WithBoolTokens([]string{"yes", "Y", "on"}, []string{"no", "N", "off"})
+
+	tests := []struct {
+		name     string
+		value    string
+		expected bool
+		wantErr  string
+	}{
+		{name: "true token", value: "yes", expected: true},
+		{name: "case-insensitive true", value: "YES", expected: true},
+		{name: "single letter", value: "y", expected: true},
+		{name: "false token", value: "Off", expected: false},
+		{name: "standard true", value: "TRUE", expected: true},
+		{name: "standard false", value: "0", expected: false},
+		{
+			name:    "invalid",
+			value:   "maybe",
+			wantErr: `row 0: setting field active: invalid bool value "maybe": accepted values are yes, Y, on for true and no, N, off for false, or those of strconv.ParseBool`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions([]string{"active"}, [][]string{{tt.value}}, &result, opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result[0].Active)
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_strictTypes(t *testing.T) {
+	type Record struct {
+		Active bool `table:"active"`
+		Count  int  `table:"count"`
+	}
+
+	tests := []struct {
+		name     string
+		opts     *tablemap.Options
+		row      []string
+		expected Record
+		wantErr  string
+	}{
+		{name: "lenient bool", opts: tablemap.DefaultOptions(), row: []string{"T", "1"}, expected: Record{Active: true, Count: 1}},
+		{name: "strict bool", opts: tablemap.DefaultOptions().WithStrictTypes(true), row: []string{"true", "1"}, expected: Record{Active: true, Count: 1}},
+		{
+			name:    "strict rejects ParseBool inputs",
+			opts:    tablemap.DefaultOptions().WithStrictTypes(true),
+			row:     []string{"1", "1"},
+			wantErr: `row 0: setting field active: invalid bool value "1": expected "true" or "false"`,
+		},
+		{
+			name:     "strict with BoolFormat",
+			opts:     tablemap.DefaultOptions().WithStrictTypes(true).WithBoolFormat("Y", "N"),
+			row:      []string{"N", "1"},
+			expected: Record{Active: false, Count: 1},
+		},
+		{
+			name:     "strict with bool tokens",
+			opts:     tablemap.DefaultOptions().WithStrictTypes(true).WithBoolTokens([]string{"yes"}, []string{"no"}),
+			row:      []string{"YES", "1"},
+			expected: Record{Active: true, Count: 1},
+		},
+		{
+			name:     "strict with zero value options",
+			opts:     &tablemap.Options{StrictTypes: true},
+			row:      []string{"false", "1"},
+			expected: Record{Active: false, Count: 1},
+		},
+		{
+			name:    "fractional integer",
+			opts:    tablemap.DefaultOptions().WithStrictTypes(true),
+			row:     []string{"true", "1.0"},
+			wantErr: `row 0: setting field count: strconv.ParseInt: parsing "1.0": invalid syntax`,
+		},
+		{name: "lenient integer", opts: tablemap.DefaultOptions(), row: []string{"true", "+007"}, expected: Record{Active: true, Count: 7}},
+		{
+			name:    "strict rejects plus sign",
+			opts:    tablemap.DefaultOptions().WithStrictTypes(true),
+			row:     []string{"true", "+1"},
+			wantErr: `row 0: setting field count: invalid integer value "+1": unexpected plus sign`,
+		},
+		{
+			name:    "strict rejects leading zeros",
+			opts:    tablemap.DefaultOptions().WithStrictTypes(true),
+			row:     []string{"true", "-007"},
+			wantErr: `row 0: setting field count: invalid integer value "-007": unexpected leading zero`,
+		},
+		{
+			name:     "strict accepts zero",
+			opts:     tablemap.DefaultOptions().WithStrictTypes(true),
+			row:      []string{"true", "0"},
+			expected: Record{Active: true, Count: 0},
+		},
+		{
+			name:     "strict with base prefix",
+			opts:     tablemap.DefaultOptions().WithStrictTypes(true).WithIntBase(tablemap.IntBaseAuto),
+			row:      []string{"true", "0x1f"},
+			expected: Record{Active: true, Count: 31},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions([]string{"active", "count"}, [][]string{tt.row}, &result, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result[0])
+		})
+	}
+}
+
+type Customer struct {
+	Name  string `table:"name"`
+	Email string `table:"email"`
+}
+
+type Order struct {
+	ID       int       `table:"id"`
+	Customer Customer  `table:"customer"`
+	Shipping *Customer `table:"shipping"`
+}
+
+func TestMarshal_nested(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          []Order
+		options        *tablemap.Options
+		expectedHeader []string
+		expectedData   [][]string
+	}{
+		{
+			name: "default separator",
+			input: []Order{
+				{
+					ID:       1,
+					Customer: Customer{Name: "John", Email: "john@example.com"},
+					Shipping: &Customer{Name: "Jane", Email: "jane@example.com"},
+				},
+			},
+			options:        nil,
+			expectedHeader: []string{"id", "customer.name", "customer.email", "shipping.name", "shipping.email"},
+			expectedData: [][]string{
+				{"1", "John", "john@example.com", "Jane", "jane@example.com"},
+			},
+		},
+		{
+			name: "nil nested pointer",
+			input: []Order{
+				{
+					ID:       1,
+					Customer: Customer{Name: "John", Email: "john@example.com"},
+				},
+			},
+			options:        nil,
+			expectedHeader: []string{"id", "customer.name", "customer.email", "shipping.name", "shipping.email"},
+			expectedData: [][]string{
+				{"1", "John", "john@example.com", "\\N", "\\N"},
+			},
+		},
+		{
+			name: "custom separator",
+			input: []Order{
+				{
+					ID:       1,
+					Customer: Customer{Name: "John", Email: "john@example.com"},
+					Shipping: &Customer{Name: "Jane", Email: "jane@example.com"},
+				},
+			},
+			options:        &tablemap.Options{NestedSeparator: "_"},
+			expectedHeader: []string{"id", "customer_name", "customer_email", "shipping_name", "shipping_email"},
+			expectedData: [][]string{
+				{"1", "John", "john@example.com", "Jane", "jane@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, data, err := tablemap.MarshalWithOptions(tt.input, tt.options)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedHeader, header)
+			assert.Equal(t, tt.expectedData, data)
+		})
+	}
+}
+
+func TestUnmarshal_nested(t *testing.T) {
+	header := []string{"id", "customer.name", "customer.email", "shipping.name", "shipping.email"}
+	data := [][]string{
+		{"1", "John", "john@example.com", "Jane", "jane@example.com"},
+	}
+
+	var result []Order
+	err := tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []Order{
+		{
+			ID:       1,
+			Customer: Customer{Name: "John", Email: "john@example.com"},
+			Shipping: &Customer{Name: "Jane", Email: "jane@example.com"},
+		},
+	}, result)
+}
+
+func TestUnmarshal_nilNestedPointer(t *testing.T) {
+	type Record struct {
+		ID      int       `table:"id"`
+		Contact *Customer `table:"contact"`
+		Note    *struct {
+			Text *string `table:"text"`
+		} `table:"note"`
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		input := []Order{
+			{ID: 1, Customer: Customer{Name: "John", Email: "john@example.com"}},
+			{ID: 2, Shipping: &Customer{Name: "Jane"}},
+		}
+
+		header, data, err := tablemap.Marshal(input)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1", "John", "john@example.com", "\\N", "\\N"}, data[0])
+
+		var result []Order
+		err = tablemap.Unmarshal(header, data, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("pointer fields stay nil", func(t *testing.T) {
+		input := []Record{{ID: 1}}
+
+		header, data, err := tablemap.Marshal(input)
+		assert.NoError(t, err)
+
+		var result []Record
+		err = tablemap.Unmarshal(header, data, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+}
+
+func TestMarshal_deeplyNested(t *testing.T) {
+	type Level3 struct {
+		A string `table:"a"`
+		B string `table:"b"`
+	}
+	type Level2 struct {
+		L3 Level3 `table:"l3"`
+	}
+	type Level1 struct {
+		L2 Level2 `table:"l2"`
+	}
+	type Root struct {
+		L1 Level1 `table:"l1"`
+	}
+
+	input := []Root{
+		{L1: Level1{L2: Level2{L3: Level3{A: "a1", B: "b1"}}}},
+	}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"l1.l2.l3.a", "l1.l2.l3.b"}, header)
+	assert.Equal(t, [][]string{{"a1", "b1"}}, data)
+
+	var result []Root
+	err = tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+}
+
+func TestMarshalVertical(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  *int   `table:"age"`
+	}
+
+	age := 30
+	expected := [][2]string{
+		{"name", "John"},
+		{"age", "30"},
+	}
+
+	tests := []struct {
+		name     string
+		input    any
+		expected [][2]string
+		wantErr  bool
+	}{
+		{
+			name:     "struct",
+			input:    Person{Name: "John", Age: &age},
+			expected: expected,
+		},
+		{
+			name:     "pointer to struct",
+			input:    &Person{Name: "John", Age: &age},
+			expected: expected,
+		},
+		{
+			name:     "slice with one element",
+			input:    []Person{{Name: "John", Age: &age}},
+			expected: expected,
+		},
+		{
+			name:  "nil value",
+			input: Person{Name: "John"},
+			expected: [][2]string{
+				{"name", "John"},
+				{"age", "\\N"},
+			},
+		},
+		{
+			name:    "slice with multiple elements",
+			input:   []Person{{Name: "John"}, {Name: "Jane"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty slice",
+			input:   []Person{},
+			wantErr: true,
+		},
+		{
+			name:    "nil pointer",
+			input:   (*Person)(nil),
+			wantErr: true,
+		},
+		{
+			name:     "pointer to pointer to struct",
+			input:    P(&Person{Name: "John", Age: &age}),
+			expected: expected,
+		},
+		{
+			name:     "slice of pointers",
+			input:    []*Person{{Name: "John", Age: &age}},
+			expected: expected,
+		},
+		{
+			name:    "nil pointer to pointer",
+			input:   P((*Person)(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "nil",
+			input:   nil,
+			wantErr: true,
+		},
+		{
+			name:    "slice with nil element",
+			input:   []any{nil},
+			wantErr: true,
+		},
+		{
+			name:    "not a struct",
+			input:   42,
+			wantErr: true,
+		},
+		{
+			name:    "pointer to non-struct",
+			input:   P(P(42)),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, err := tablemap.MarshalVertical(tt.input, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, pairs)
+		})
+	}
+}
+
+func TestMarshalVertical_nonStructError(t *testing.T) {
+	_, err := tablemap.MarshalVertical(P(P(42)), nil)
+	assert.EqualError(t, err, "v must be a struct or pointer to struct, got int")
+}
+
+func TestUnmarshal_pointerElements(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	header := []string{"name", "age"}
+	data := [][]string{
+		{"Alice", "23"},
+		{"Bob", "25"},
+	}
+
+	var result []*Person
+	err := tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Person{
+		{Name: "Alice", Age: 23},
+		{Name: "Bob", Age: 25},
+	}, result)
+	for _, p := range result {
+		assert.NotNil(t, p)
+	}
+
+	var invalid []*int
+	err = tablemap.Unmarshal(header, data, &invalid)
+	assert.Error(t, err)
+
+	var invalidPtrPtr []**int
+	err = tablemap.Unmarshal(header, data, &invalidPtrPtr)
+	assert.Error(t, err)
+}
+
+func TestUnmarshal_multiLevelPointerElements(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	header := []string{"name", "age"}
+	data := [][]string{
+		{"Alice", "23"},
+		{"Bob", "25"},
+	}
+
+	var result []**Person
+	err := tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, Person{Name: "Alice", Age: 23}, **result[0])
+	assert.Equal(t, Person{Name: "Bob", Age: 25}, **result[1])
+	// Every level is allocated for each row
+	assert.NotSame(t, result[0], result[1])
+	assert.NotSame(t, *result[0], *result[1])
+
+	var array [2]***Person
+	err = tablemap.Unmarshal(header, data, &array)
+	assert.NoError(t, err)
+	assert.Equal(t, Person{Name: "Bob", Age: 25}, ***array[1])
+
+	var positional []**Person
+	err = tablemap.UnmarshalPositional(data, &positional, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Person{Name: "Alice", Age: 23}, **positional[0])
+}
+
+func TestUnmarshalWithOptions_trimSpace(t *testing.T) {
+	type Record struct {
+		Name   string  `table:"name"`
+		Age    int     `table:"age"`
+		Score  float64 `table:"score"`
+		Active bool    `table:"active"`
+		Ptr    *int    `table:"ptr"`
+	}
+
+	header := []string{"name", "age", "score", "active", "ptr"}
+
+	tests := []struct {
+		name     string
+		data     [][]string
+		options  *tablemap.Options
+		expected []Record
+		wantErr  bool
+	}{
+		{
+			name:    "without TrimSpace",
+			data:    [][]string{{" John ", "  42 ", "1.5", "true", "1"}},
+			options: nil,
+			wantErr: true,
+		},
+		{
+			name:    "with TrimSpace",
+			data:    [][]string{{" John ", "  42 ", " 1.5\t", " true ", " 1 "}},
+			options: &tablemap.Options{NilValue: "\\N", TrimSpace: true},
+			expected: []Record{
+				{Name: "John", Age: 42, Score: 1.5, Active: true, Ptr: P(1)},
+			},
+		},
+		{
+			name:    "blank pointer becomes nil",
+			data:    [][]string{{"John", "42", "1.5", "true", "   "}},
+			options: &tablemap.Options{NilValue: "\\N", TrimSpace: true},
+			expected: []Record{
+				{Name: "John", Age: 42, Score: 1.5, Active: true, Ptr: nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions(header, tt.data, &result, tt.options)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+type jsonMeta struct {
+	Version int    `json:"version"`
+	Note    string `json:"note"`
+}
+
+type jsonTestStruct struct {
+	ID      int               `table:"id"`
+	Map     map[string]string `table:"map,json"`
+	Slice   []int             `table:"slice,json"`
+	Struct  jsonMeta          `table:"struct,json"`
+	Pointer *jsonMeta         `table:"pointer,json"`
+}
+
+func TestMarshal_json(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []jsonTestStruct
+		expected [][]string
+	}{
+		{
+			name: "all values",
+			input: []jsonTestStruct{
+				{
+					ID:      1,
+					Map:     map[string]string{"a": "<b>"},
+					Slice:   []int{1, 2, 3},
+					Struct:  jsonMeta{Version: 1, Note: "x"},
+					Pointer: &jsonMeta{Version: 2, Note: "y"},
+				},
+			},
+			expected: [][]string{
+				{"1", `{"a":"<b>"}`, "[1,2,3]", `{"version":1,"note":"x"}`, `{"version":2,"note":"y"}`},
+			},
+		},
+		{
+			name: "separators in elements",
+			input: []jsonTestStruct{
+				{ID: 1, Map: map[string]string{"a,b": "c;d=e"}},
+			},
+			expected: [][]string{
+				{"1", `{"a,b":"c;d=e"}`, "\\N", `{"version":0,"note":""}`, "\\N"},
+			},
+		},
+		{
+			name: "nil values",
+			input: []jsonTestStruct{
+				{ID: 1},
+			},
+			expected: [][]string{
+				{"1", "\\N", "\\N", `{"version":0,"note":""}`, "\\N"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, data, err := tablemap.Marshal(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"id", "map", "slice", "struct", "pointer"}, header)
+			assert.Equal(t, tt.expected, data)
+
+			// Round trip
+			var result []jsonTestStruct
+			err = tablemap.Unmarshal(header, data, &result)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.input, result)
+		})
+	}
+}
+
+func TestUnmarshal_json(t *testing.T) {
+	header := []string{"id", "map", "slice", "struct", "pointer"}
+
+	tests := []struct {
+		name     string
+		data     [][]string
+		expected []jsonTestStruct
+		wantErr  bool
+	}{
+		{
+			name: "empty cells",
+			data: [][]string{{"1", "", "", "", ""}},
+			expected: []jsonTestStruct{
+				{ID: 1},
+			},
+		},
+		{
+			name:    "invalid json",
+			data:    [][]string{{"1", "{", "", "", ""}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []jsonTestStruct
+			err := tablemap.Unmarshal(header, tt.data, &result)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestUnmarshal_required(t *testing.T) {
+	type Record struct {
+		ID    string `table:"id,required"`
+		Score *int   `table:"score,required"`
+		Note  string `table:"note"`
+	}
+	header := []string{"id", "score", "note"}
+
+	tests := []struct {
+		name     string
+		opts     *tablemap.Options
+		data     [][]string
+		expected []Record
+		wantErr  string
+	}{
+		{
+			name:     "present",
+			data:     [][]string{{"a1", "10", ""}},
+			expected: []Record{{ID: "a1", Score: P(10)}},
+		},
+		{
+			name:    "empty cell",
+			data:    [][]string{{"a1", "10", ""}, {"", "20", "x"}},
+			wantErr: "row 1: setting field id: required value is empty",
+		},
+		{
+			name:    "nil cell",
+			data:    [][]string{{"a1", "\\N", ""}},
+			wantErr: "row 0: setting field score: required value is empty",
+		},
+		{
+			name:    "blank cell with TrimSpace",
+			opts:    tablemap.DefaultOptions().WithTrimSpace(true),
+			data:    [][]string{{"  ", "1", ""}},
+			wantErr: "row 0: setting field id: required value is empty",
+		},
+		{
+			name:     "blank cell without TrimSpace",
+			data:     [][]string{{"  ", "1", ""}},
+			expected: []Record{{ID: "  ", Score: P(1)}},
+		},
+		{
+			name:     "default value",
+			opts:     tablemap.DefaultOptions().WithDefaultValues(map[string]string{"id": "unknown"}),
+			data:     [][]string{{"", "1", ""}},
+			expected: []Record{{ID: "unknown", Score: P(1)}},
+		},
+		{
+			name:    "missing in ragged row",
+			opts:    tablemap.DefaultOptions().WithAllowRaggedRows(true),
+			data:    [][]string{{"a1"}},
+			wantErr: "row 0: setting field score: required value is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions(header, tt.data, &result, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("missing columns", func(t *testing.T) {
+		var result []Record
+		err := tablemap.Unmarshal([]string{"note"}, [][]string{{"x"}}, &result)
+		assert.EqualError(t, err, "missing required columns: id, score")
+
+		_, err = tablemap.NewRowHandler[Record]([]string{"id", "note"}, nil)
+		assert.EqualError(t, err, "missing required columns: score")
+
+		handler, err := tablemap.NewRowHandler[Record](header, nil)
+		assert.NoError(t, err)
+		assert.EqualError(t, handler.Reset([]string{"score"}), "missing required columns: id")
+	})
+
+	t.Run("marshal is not affected", func(t *testing.T) {
+		_, data, err := tablemap.Marshal([]Record{{}})
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"", "\\N", ""}}, data)
+
+		// Required columns may be left out of the marshaled columns
+		_, data, err = tablemap.MarshalOrdered([]Record{{Note: "x"}}, []string{"note"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"x"}}, data)
+
+		handler, err := tablemap.NewRowHandler[Record](nil, tablemap.DefaultOptions().WithHeader([]string{"note"}))
+		assert.NoError(t, err)
+		row, err := handler.MarshalRow(&Record{Note: "x"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"x"}, row)
+	})
+}
+
+func TestUnmarshal_tagDefault(t *testing.T) {
+	type Record struct {
+		Name    string  `table:"name"`
+		Country string  `table:"country,default=JP"`
+		Score   *int    `table:"score,default=0"`
+		Note    string  `table:"note,default=a,b=c"`
+		Tags    []int   `table:"tags,json,default=[1,2]"`
+		ID      string  `table:"id,required,default=none"`
+		Rate    float64 `table:"rate,default=0.5"`
+	}
+	header := []string{"name", "country", "score", "note", "tags", "id", "rate"}
+
+	tests := []struct {
+		name     string
+		opts     *tablemap.Options
+		data     [][]string
+		expected []Record
+		wantErr  string
+	}{
+		{
+			name: "empty and nil cells",
+			data: [][]string{{"Alice", "", "\\N", "", "", "", "1.5"}},
+			expected: []Record{
+				{Name: "Alice", Country: "JP", Score: P(0), Note: "a,b=c", Tags: []int{1, 2}, ID: "none", Rate: 1.5},
+			},
+		},
+		{
+			name: "present cells",
+			data: [][]string{{"Bob", "US", "3", "n", "[3]", "b1", "2"}},
+			expected: []Record{
+				{Name: "Bob", Country: "US", Score: P(3), Note: "n", Tags: []int{3}, ID: "b1", Rate: 2},
+			},
+		},
+		{
+			name: "options take precedence",
+			opts: tablemap.DefaultOptions().WithDefaultValues(map[string]string{"country": "FR"}),
+			data: [][]string{{"Carol", "", "1", "n", "[]", "c1", "0"}},
+			expected: []Record{
+				{Name: "Carol", Country: "FR", Score: P(1), Note: "n", Tags: []int{}, ID: "c1"},
+			},
+		},
+		{
+			name: "missing cells of ragged rows",
+			opts: tablemap.DefaultOptions().WithAllowRaggedRows(true),
+			data: [][]string{{"Dave", "", "2"}},
+			expected: []Record{
+				{Name: "Dave", Country: "JP", Score: P(2), Note: "a,b=c", Tags: []int{1, 2}, ID: "none", Rate: 0.5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions(header, tt.data, &result, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("invalid default", func(t *testing.T) {
+		type Invalid struct {
+			Rate float64 `table:"rate,default=x"`
+		}
+		var result []Invalid
+		err := tablemap.Unmarshal([]string{"rate"}, [][]string{{""}}, &result)
+		assert.EqualError(t, err, `row 0: setting field rate: strconv.ParseFloat: parsing "x": invalid syntax`)
+	})
+
+	t.Run("marshal is not affected", func(t *testing.T) {
+		header, data, err := tablemap.Marshal([]Record{{}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name", "country", "score", "note", "tags", "id", "rate"}, header)
+		assert.Equal(t, [][]string{{"", "", "\\N", "", "\\N", "", "0"}}, data)
+	})
+}
+
+func TestMarshal_tagIndex(t *testing.T) {
+	type Base struct {
+		ID int `table:"id,index=0"`
+	}
+	type Record struct {
+		Name  string `table:"name"`
+		Email string `table:"email,index=3"`
+		Age   int    `table:"age"`
+		Base
+		Note string `table:"note,required,index=1"`
+	}
+	type Conflict struct {
+		A string `table:"a,index=1"`
+		B string `table:"b,index=1"`
+	}
+	type OutOfRange struct {
+		A string `table:"a,index=2"`
+		B string `table:"b"`
+	}
+	type Invalid struct {
+		A string `table:"a,index=x"`
+	}
+	type DefaultValue struct {
+		A string `table:"a,default=index=1"`
+		B string `table:"b"`
+	}
+
+	tests := []struct {
+		name           string
+		v              any
+		expectedHeader []string
+		expectedData   [][]string
+		wantErr        string
+	}{
+		{
+			name:           "pinned and declaration order",
+			v:              []Record{{Name: "Alice", Email: "a@example.com", Age: 23, Base: Base{ID: 1}, Note: "n"}},
+			expectedHeader: []string{"id", "note", "name", "email", "age"},
+			expectedData:   [][]string{{"1", "n", "Alice", "a@example.com", "23"}},
+		},
+		{
+			name:    "conflicting indices",
+			v:       []Conflict{},
+			wantErr: "columns a and b both have index 1",
+		},
+		{
+			name:    "index out of range",
+			v:       []OutOfRange{},
+			wantErr: "column a: index 2 is out of range for 2 columns",
+		},
+		{
+			name:    "invalid index",
+			v:       []Invalid{},
+			wantErr: `column a: invalid index option: "x"`,
+		},
+		{
+			name:           "part of a default value",
+			v:              []DefaultValue{{A: "a", B: "b"}},
+			expectedHeader: []string{"a", "b"},
+			expectedData:   [][]string{{"a", "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, data, err := tablemap.Marshal(tt.v)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedHeader, header)
+			assert.Equal(t, tt.expectedData, data)
+		})
+	}
+
+	t.Run("unmarshal by header", func(t *testing.T) {
+		var result []Record
+		err := tablemap.Unmarshal([]string{"name", "id", "note", "age", "email"}, [][]string{{"Bob", "2", "m", "45", "b@example.com"}}, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{{Name: "Bob", Email: "b@example.com", Age: 45, Base: Base{ID: 2}, Note: "m"}}, result)
+	})
+}
+func TestUnmarshalWithOptions_fieldErrorHandler(t *testing.T) {
+	type Record struct {
+		Name  string `table:"name"`
+		Age   int    `table:"age"`
+		Score *int   `table:"score"`
+	}
+	header := []string{"name", "age", "score"}
+	data := [][]string{
+		{"Alice", "23", "10"},
+		{"Bob", "old", "x"},
+		{"Carol", "31", "y"},
+	}
+
+	type call struct {
+		row    int
+		column string
+		value  string
+	}
+
+	t.Run("skip some columns", func(t *testing.T) {
+		var calls []call
+		opts := tablemap.DefaultOptions().WithFieldErrorHandler(func(row int, column, value string, err error) error {
+			assert.Error(t, err)
+			calls = append(calls, call{row, column, value})
+			if column == "score" {
+				return nil
+			}
+			return err
+		})
+
+		var result []Record
+		err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.EqualError(t, err, `row 1: setting field age: strconv.ParseInt: parsing "old": invalid syntax`)
+		assert.Equal(t, []call{{1, "age", "old"}}, calls)
+
+		calls = nil
+		result = nil
+		err = tablemap.UnmarshalWithOptions(header, [][]string{data[0], data[2]}, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{{Name: "Alice", Age: 23, Score: P(10)}, {Name: "Carol", Age: 31}}, result)
+		assert.Equal(t, []call{{1, "score", "y"}}, calls)
+	})
+
+	t.Run("skip all", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithFieldErrorHandler(func(int, string, string, error) error {
+			return nil
+		})
+
+		var result []Record
+		err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{
+			{Name: "Alice", Age: 23, Score: P(10)},
+			{Name: "Bob"},
+			{Name: "Carol", Age: 31},
+		}, result)
+	})
+
+	t.Run("replace the error", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithFieldErrorHandler(func(row int, column, value string, err error) error {
+			return errors.New("bad " + column)
+		})
+
+		var result []Record
+		err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.EqualError(t, err, "row 1: setting field age: bad age")
+	})
+
+	t.Run("row handler counts rows", func(t *testing.T) {
+		var rows []int
+		opts := tablemap.DefaultOptions().WithFieldErrorHandler(func(row int, column, value string, err error) error {
+			rows = append(rows, row)
+			return nil
+		})
+
+		handler, err := tablemap.NewRowHandler[Record](header, opts)
+		assert.NoError(t, err)
+		for _, row := range data {
+			_, err := handler.UnmarshalRow(row)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, []int{1, 1, 2}, rows)
+
+		assert.NoError(t, handler.Reset(header))
+		_, err = handler.UnmarshalRow(data[1])
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 1, 2, 0, 0}, rows)
+	})
+}
+
+func TestMarshalWithOptions_floatFormat(t *testing.T) {
+	type Record struct {
+		F64 float64  `table:"f64"`
+		Ptr *float64 `table:"ptr"`
+	}
+
+	input := []Record{
+		{F64: 0.1, Ptr: P(1234.5678)},
+		{F64: 1e21, Ptr: P(0.000012)},
+	}
+
+	tests := []struct {
+		name     string
+		options  *tablemap.Options
+		expected [][]string
+	}{
+		{
+			name:    "default options",
+			options: nil,
+			expected: [][]string{
+				{"0.1", "1234.5678"},
+				{"1000000000000000000000", "0.000012"},
+			},
+		},
+		{
+			name:    "zero value options",
+			options: &tablemap.Options{},
+			expected: [][]string{
+				{"0.1", "1234.5678"},
+				{"1000000000000000000000", "0.000012"},
+			},
+		},
+		{
+			name:    "fixed precision",
+			options: &tablemap.Options{FloatFormat: 'f', FloatPrecision: 2},
+			expected: [][]string{
+				{"0.10", "1234.57"},
+				{"1000000000000000000000.00", "0.00"},
+			},
+		},
+		{
+			name:    "scientific notation",
+			options: &tablemap.Options{FloatFormat: 'e', FloatPrecision: -1},
+			expected: [][]string{
+				{"1e-01", "1.2345678e+03"},
+				{"1e+21", "1.2e-05"},
+			},
+		},
+		{
+			name:    "shortest representation",
+			options: &tablemap.Options{FloatFormat: 'g', FloatPrecision: -1},
+			expected: [][]string{
+				{"0.1", "1234.5678"},
+				{"1e+21", "1.2e-05"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, data, err := tablemap.MarshalWithOptions(input, tt.options)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+}
+
+func P[T any](t T) *T {
+	return &t
+}
+
+func TestUnmarshal_maps(t *testing.T) {
+	header := []string{"name", "age"}
+	data := [][]string{
+		{"Alice", "23"},
+		{"Bob", "\\N"},
+	}
+
+	t.Run("map[string]string", func(t *testing.T) {
+		var result []map[string]string
+		err := tablemap.Unmarshal(header, data, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, []map[string]string{
+			{"name": "Alice", "age": "23"},
+			{"name": "Bob", "age": "\\N"},
+		}, result)
+	})
+
+	t.Run("map[string]any", func(t *testing.T) {
+		var result []map[string]any
+		err := tablemap.Unmarshal(header, data, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, []map[string]any{
+			{"name": "Alice", "age": "23"},
+			{"name": "Bob", "age": "\\N"},
+		}, result)
+	})
+
+	t.Run("named string types", func(t *testing.T) {
+		type Key string
+		type Value string
+		var result []map[Key]Value
+		err := tablemap.Unmarshal(header, data, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, []map[Key]Value{
+			{"name": "Alice", "age": "23"},
+			{"name": "Bob", "age": "\\N"},
+		}, result)
+	})
+
+	t.Run("unsupported key type", func(t *testing.T) {
+		var result []map[int]string
+		err := tablemap.Unmarshal(header, data, &result)
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported value type", func(t *testing.T) {
+		var result []map[string]int
+		err := tablemap.Unmarshal(header, data, &result)
+		assert.Error(t, err)
+	})
+
+	t.Run("inconsistent data length", func(t *testing.T) {
+		var result []map[string]string
+		err := tablemap.Unmarshal(header, [][]string{{"Alice"}}, &result)
+		assert.Error(t, err)
+	})
+}
+
+func TestUnmarshalWithOptions_headerAliases(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	tests := []struct {
+		name     string
+		header   []string
+		options  *tablemap.Options
+		expected []Person
+	}{
+		{
+			name:   "alias",
+			header: []string{"full_name", "age"},
+			options: &tablemap.Options{
+				HeaderAliases: map[string]string{"full_name": "name"},
+			},
+			expected: []Person{{Name: "John", Age: 30}},
+		},
+		{
+			name:   "tag still matches",
+			header: []string{"name", "age"},
+			options: &tablemap.Options{
+				HeaderAliases: map[string]string{"full_name": "name"},
+			},
+			expected: []Person{{Name: "John", Age: 30}},
+		},
+		{
+			name:   "output alias is accepted",
+			header: []string{"Name", "Age"},
+			options: &tablemap.Options{
+				OutputAliases: map[string]string{"name": "Name", "age": "Age"},
+			},
+			expected: []Person{{Name: "John", Age: 30}},
+		},
+		{
+			name:     "no alias",
+			header:   []string{"full_name", "age"},
+			options:  nil,
+			expected: []Person{{Name: "", Age: 30}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Person
+			err := tablemap.UnmarshalWithOptions(tt.header, [][]string{{"John", "30"}}, &result, tt.options)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMarshalWithOptions_outputAliases(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	opts := &tablemap.Options{
+		OutputAliases: map[string]string{"name": "Full Name"},
+	}
+
+	input := []Person{{Name: "John", Age: 30}}
+	header, data, err := tablemap.MarshalWithOptions(input, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Full Name", "age"}, header)
+	assert.Equal(t, [][]string{{"John", "30"}}, data)
+
+	// Round trip
+	var result []Person
+	err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+}
+
+func TestMarshal_duration(t *testing.T) {
+	type Record struct {
+		Duration time.Duration  `table:"duration"`
+		Ptr      *time.Duration `table:"ptr"`
+		Int64    int64          `table:"int64"`
+	}
+
+	input := []Record{
+		{Duration: 90 * time.Minute, Ptr: P(1500 * time.Millisecond), Int64: 3600},
+		{Duration: 0, Ptr: nil, Int64: 0},
+	}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"duration", "ptr", "int64"}, header)
+	assert.Equal(t, [][]string{
+		{"1h30m0s", "1.5s", "3600"},
+		{"0s", "\\N", "0"},
+	}, data)
+
+	// Round trip
+	var result []Record
+	err = tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+}
+
+func TestUnmarshal_duration(t *testing.T) {
+	type Record struct {
+		Duration time.Duration `table:"duration"`
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{name: "hours and minutes", value: "1h30m", expected: 90 * time.Minute},
+		{name: "milliseconds", value: "250ms", expected: 250 * time.Millisecond},
+		{name: "nanosecond count is rejected", value: "3600000000000", wantErr: true},
+		{name: "invalid", value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.Unmarshal([]string{"duration"}, [][]string{{tt.value}}, &result)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result[0].Duration)
+		})
+	}
+}
+
+func TestUnmarshal_overflow(t *testing.T) {
+	type Record struct {
+		Int8    int8    `table:"int8"`
+		Uint8   uint8   `table:"uint8"`
+		Int16   int16   `table:"int16"`
+		Float32 float32 `table:"float32"`
+	}
+
+	tests := []struct {
+		name     string
+		column   string
+		value    string
+		expected Record
+		wantErr  string
+	}{
+		{name: "int8 max", column: "int8", value: "127", expected: Record{Int8: 127}},
+		{name: "int8 min", column: "int8", value: "-128", expected: Record{Int8: -128}},
+		{name: "int8 overflow", column: "int8", value: "128", wantErr: `row 0: setting field int8: value "128" overflows int8: strconv.ParseInt: parsing "128": value out of range`},
+		{name: "int8 underflow", column: "int8", value: "-129", wantErr: `row 0: setting field int8: value "-129" overflows int8: strconv.ParseInt: parsing "-129": value out of range`},
+		{name: "uint8 max", column: "uint8", value: "255", expected: Record{Uint8: 255}},
+		{name: "uint8 overflow", column: "uint8", value: "300", wantErr: `row 0: setting field uint8: value "300" overflows uint8: strconv.ParseUint: parsing "300": value out of range`},
+		{name: "uint8 negative", column: "uint8", value: "-1", wantErr: `row 0: setting field uint8: strconv.ParseUint: parsing "-1": invalid syntax`},
+		{name: "int16 overflow", column: "int16", value: "40000", wantErr: `row 0: setting field int16: value "40000" overflows int16: strconv.ParseInt: parsing "40000": value out of range`},
+		{name: "float32 overflow", column: "float32", value: "1e39", wantErr: `row 0: setting field float32: value "1e39" overflows float32: strconv.ParseFloat: parsing "1e39": value out of range`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.Unmarshal([]string{tt.column}, [][]string{{tt.value}}, &result)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []Record{tt.expected}, result)
+		})
+	}
+
+	t.Run("enum overflow", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithEnumMaps(map[reflect.Type]map[string]int64{
+			reflect.TypeOf(enumLevel(0)): {"Huge": 1000},
+		})
+		type Record struct {
+			Level enumLevel `table:"level"`
+		}
+
+		var result []Record
+		err := tablemap.UnmarshalWithOptions([]string{"level"}, [][]string{{"Huge"}}, &result, opts)
+		assert.EqualError(t, err, `row 0: setting field level: value 1000 of "Huge" overflows tablemap_test.enumLevel`)
+	})
+}
+
+func TestMarshal_big(t *testing.T) {
+	type Record struct {
+		IntPtr   *big.Int   `table:"int_ptr"`
+		Int      big.Int    `table:"int"`
+		FloatPtr *big.Float `table:"float_ptr"`
+	}
+
+	const (
+		largeInt   = "123456789012345678901234567890123456789012345678901234567890"
+		largeFloat = "98765432109876543210987654321.12345678901234567890123456789"
+	)
+
+	header := []string{"int_ptr", "int", "float_ptr"}
+	data := [][]string{
+		{largeInt, "-" + largeInt, largeFloat},
+		{"0", "42", "0.1"},
+		{"\\N", "0", "\\N"},
+	}
+
+	var result []Record
+	err := tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+
+	expectedInt, _ := new(big.Int).SetString(largeInt, 10)
+	assert.Equal(t, 0, expectedInt.Cmp(result[0].IntPtr))
+	assert.Equal(t, 0, new(big.Int).Neg(expectedInt).Cmp(&result[0].Int))
+	assert.Nil(t, result[2].IntPtr)
+	assert.Nil(t, result[2].FloatPtr)
+
+	// Every digit of a large big.Float is kept
+	assert.Equal(t, largeFloat, result[0].FloatPtr.Text('f', -1))
+
+	// Round trip
+	marshaledHeader, marshaled, err := tablemap.Marshal(result)
+	assert.NoError(t, err)
+	assert.Equal(t, header, marshaledHeader)
+	assert.Equal(t, data, marshaled)
+}
+
+func TestMarshalWithOptions_bigFloatFormat(t *testing.T) {
+	type Record struct {
+		Value *big.Float `table:"value"`
+	}
+
+	input := []Record{{Value: big.NewFloat(1234.5)}}
+
+	_, data, err := tablemap.MarshalWithOptions(input, tablemap.DefaultOptions().WithFloatFormat('e', 2))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"1.23e+03"}}, data)
+}
+
+func TestUnmarshal_bigInvalid(t *testing.T) {
+	type Record struct {
+		Int   *big.Int   `table:"int"`
+		Float *big.Float `table:"float"`
+	}
+
+	var result []Record
+	err := tablemap.Unmarshal([]string{"int"}, [][]string{{"12x"}}, &result)
+	assert.Error(t, err)
+
+	err = tablemap.Unmarshal([]string{"float"}, [][]string{{"1.2.3"}}, &result)
+	assert.Error(t, err)
+}
+
+type enumStatus int
+
+const (
+	enumStatusActive enumStatus = iota
+	enumStatusInactive
+)
+
+type enumLevel uint8
+
+func TestMarshal_enumMaps(t *testing.T) {
+	type Record struct {
+		Status enumStatus  `table:"status"`
+		Ptr    *enumStatus `table:"ptr"`
+		Level  enumLevel   `table:"level"`
+		Count  int         `table:"count"`
+	}
+
+	opts := tablemap.DefaultOptions().WithEnumMaps(map[reflect.Type]map[string]int64{
+		reflect.TypeOf(enumStatus(0)): {"Active": int64(enumStatusActive), "Inactive": int64(enumStatusInactive)},
+		reflect.TypeOf(enumLevel(0)):  {"Low": 1, "High": 2},
+	})
+
+	input := []Record{
+		{Status: enumStatusActive, Ptr: P(enumStatusInactive), Level: 1, Count: 1},
+		{Status: enumStatusInactive, Ptr: nil, Level: 2, Count: 0},
+	}
+
+	header, data, err := tablemap.MarshalWithOptions(input, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"status", "ptr", "level", "count"}, header)
+	assert.Equal(t, [][]string{
+		{"Active", "Inactive", "Low", "1"},
+		{"Inactive", "\\N", "High", "0"},
+	}, data)
+
+	// Round trip
+	var result []Record
+	err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+
+	t.Run("unknown name", func(t *testing.T) {
+		var result []Record
+		err := tablemap.UnmarshalWithOptions([]string{"status"}, [][]string{{"Deleted"}}, &result, opts)
+		assert.ErrorContains(t, err, `unknown name "Deleted" for enum type tablemap_test.enumStatus`)
+	})
+
+	t.Run("value without a name", func(t *testing.T) {
+		header, data, err := tablemap.MarshalWithOptions([]Record{{Status: 5, Level: 1}}, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, "5", data[0][0])
+
+		// Round trip
+		var result []Record
+		err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{{Status: 5, Level: 1}}, result)
+	})
+
+	t.Run("number out of range", func(t *testing.T) {
+		var result []Record
+		err := tablemap.UnmarshalWithOptions([]string{"level"}, [][]string{{"-1"}}, &result, opts)
+		assert.ErrorContains(t, err, `value -1 of "-1" overflows tablemap_test.enumLevel`)
+	})
+
+	t.Run("without mapping", func(t *testing.T) {
+		_, data, err := tablemap.Marshal(input[:1])
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0", "1", "1", "1"}, data[0])
+	})
+}
+
+func TestMarshal_mapField(t *testing.T) {
+	type Record struct {
+		Name   string            `table:"name"`
+		Attrs  map[string]string `table:"attrs"`
+		Counts map[int]float64   `table:"counts"`
+	}
+
+	input := []Record{
+		{Name: "a", Attrs: map[string]string{"size": "L", "color": "red"}, Counts: map[int]float64{2: 0.5, 1: 3}},
+		{Name: "b", Attrs: map[string]string{}, Counts: nil},
+	}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "attrs", "counts"}, header)
+	assert.Equal(t, [][]string{
+		{"a", "color=red;size=L", "1=3;2=0.5"},
+		{"b", "", "\\N"},
+	}, data)
+
+	// Round trip
+	var result []Record
+	err = tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+
+	t.Run("custom separators", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithMapSeparators("|", ":")
+		_, data, err := tablemap.MarshalWithOptions(input[:1], opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "color:red|size:L", "1:3|2:0.5"}, data[0])
+
+		var result []Record
+		err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, input[:1], result)
+	})
+
+	t.Run("escaped separators", func(t *testing.T) {
+		type Escaped struct {
+			Attrs map[string]string `table:"attrs"`
+			Ptrs  map[string]*int   `table:"ptrs"`
+		}
+		input := []Escaped{
+			{Attrs: map[string]string{"a;b": "c=d", `e\`: `\;`, "": ""}, Ptrs: map[string]*int{"x": nil, "y": P(1)}},
+		}
+		opts := tablemap.DefaultOptions().WithEscapeMapSeparators(true)
+
+		header, data, err := tablemap.MarshalWithOptions(input, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`=;a\;b=c\=d;e\\=\\\;`, `x=\\N;y=1`}, data[0])
+
+		var result []Escaped
+		err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+
+		// Without escaping, the separators break the entries apart
+		_, data, err = tablemap.Marshal(input)
+		assert.NoError(t, err)
+		err = tablemap.Unmarshal(header, data, &result)
+		assert.Error(t, err)
+	})
+
+	t.Run("escaped nil collisions", func(t *testing.T) {
+		type Escaped struct {
+			Attrs map[string]string  `table:"attrs"`
+			Ptrs  map[string]*string `table:"ptrs"`
+		}
+		input := []Escaped{
+			{Attrs: map[string]string{"a": `\N`, `\N`: "b"}, Ptrs: map[string]*string{"x": nil, "y": P(`\N`)}},
+		}
+		opts := tablemap.DefaultOptions().WithEscapeMapSeparators(true)
+
+		header, data, err := tablemap.MarshalWithOptions(input, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`\\\\N=b;a=\\\\N`, `x=\\N;y=\\\\N`}, data[0])
+
+		var result []Escaped
+		err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("escaped custom separators", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithMapSeparators("||", ":").WithEscapeMapSeparators(true)
+		input := []Record{{Name: "a", Attrs: map[string]string{"k|": "v||w:x"}, Counts: map[int]float64{}}}
+
+		_, data, err := tablemap.MarshalWithOptions(input, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", `k|:v\||w\:x`, ""}, data[0])
+
+		var result []Record
+		err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("invalid escaped entry", func(t *testing.T) {
+		var result []Record
+		opts := tablemap.DefaultOptions().WithEscapeMapSeparators(true)
+		err := tablemap.UnmarshalWithOptions(header, [][]string{{"a", `k\=v`, ""}}, &result, opts)
+		assert.ErrorContains(t, err, `invalid map entry: "k=v"`)
+	})
+}
+
+func TestUnmarshal_mapFieldInvalid(t *testing.T) {
+	type Record struct {
+		Counts map[string]int `table:"counts"`
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{name: "missing separator", value: "a=1;b", wantErr: `row 0: setting field counts: invalid map entry: "b"`},
+		{name: "invalid value", value: "a=x", wantErr: `row 0: setting field counts: map value for key "a": strconv.ParseInt: parsing "x": invalid syntax`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.Unmarshal([]string{"counts"}, [][]string{{tt.value}}, &result)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMarshalWithOptions_time(t *testing.T) {
+	type Record struct {
+		At  time.Time  `table:"at"`
+		Ptr *time.Time `table:"ptr"`
+	}
+
+	jst := time.FixedZone("JST", 9*60*60)
+
+	t.Run("default layout", func(t *testing.T) {
+		input := []Record{{At: time.Date(2024, 1, 1, 9, 0, 0, 500, jst)}}
+
+		header, data, err := tablemap.Marshal(input)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"2024-01-01T09:00:00.0000005+09:00", "\\N"}}, data)
+
+		var result []Record
+		err = tablemap.Unmarshal(header, data, &result)
+		assert.NoError(t, err)
+		assert.True(t, input[0].At.Equal(result[0].At))
+	})
+
+	t.Run("naive timestamps in a location", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithTimeLayout(time.DateTime).WithLocation(jst)
+
+		var result []Record
+		err := tablemap.UnmarshalWithOptions([]string{"at", "ptr"}, [][]string{{"2024-01-01 09:00:00", "2024-01-01 10:00:00"}}, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), result[0].At.UTC())
+		assert.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), result[0].Ptr.UTC())
+
+		// Times are converted to the location when marshaling
+		_, data, err := tablemap.MarshalWithOptions([]Record{{At: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-01-01 09:00:00", data[0][0])
+	})
+
+	t.Run("naive timestamps default to UTC", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithTimeLayout(time.DateTime)
+
+		var result []Record
+		err := tablemap.UnmarshalWithOptions([]string{"at"}, [][]string{{"2024-01-01 09:00:00"}}, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), result[0].At)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var result []Record
+		err := tablemap.Unmarshal([]string{"at"}, [][]string{{"2024-01-01 09:00:00"}}, &result)
+		assert.Error(t, err)
+	})
+}
+
+func TestMarshal_tagLayout(t *testing.T) {
+	type Record struct {
+		DOB   time.Time  `table:"dob,layout=2006-01-02"`
+		Start *time.Time `table:"start,layout=15:04:05,required"`
+		At    time.Time  `table:"at"`
+	}
+	header := []string{"dob", "start", "at"}
+	input := []Record{{
+		DOB:   time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		Start: P(time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC)),
+		At:    time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC),
+	}}
+
+	t.Run("round trip", func(t *testing.T) {
+		h, data, err := tablemap.Marshal(input)
+		assert.NoError(t, err)
+		assert.Equal(t, header, h)
+		assert.Equal(t, [][]string{{"2024-01-15", "09:30:00", "2024-01-15T09:30:00Z"}}, data)
+
+		var result []Record
+		err = tablemap.Unmarshal(h, data, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("overrides TimeLayout", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithTimeLayout(time.DateTime)
+
+		_, data, err := tablemap.MarshalWithOptions(input, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"2024-01-15", "09:30:00", "2024-01-15 09:30:00"}}, data)
+
+		var result []Record
+		err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var result []Record
+		err := tablemap.Unmarshal(header, [][]string{{"2024-01-15T00:00:00Z", "09:30:00", "2024-01-15T09:30:00Z"}}, &result)
+		assert.ErrorContains(t, err, "setting field dob")
+	})
+}
+
+func TestUnmarshalWithOptions_collectErrors(t *testing.T) {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	header := []string{"name", "age"}
+	data := [][]string{
+		{"Alice", "23"},
+		{"Bob", "x"},
+		{"Charlie", "27"},
+		{"Dave"},
+	}
+
+	t.Run("stop at first error", func(t *testing.T) {
+		var result []Person
+		err := tablemap.UnmarshalWithOptions(header, data, &result, nil)
+
+		var unmarshalErr *tablemap.UnmarshalError
+		assert.ErrorAs(t, err, &unmarshalErr)
+		assert.Equal(t, 1, unmarshalErr.Row)
+		assert.Equal(t, []Person{{Name: "Alice", Age: 23}}, result)
+	})
+
+	t.Run("collect errors", func(t *testing.T) {
+		var result []Person
+		opts := tablemap.DefaultOptions()
+		opts.CollectErrors = true
+		err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.Error(t, err)
+
+		joined, ok := err.(interface{ Unwrap() []error })
+		assert.True(t, ok)
+		var rows []int
+		for _, e := range joined.Unwrap() {
+			var unmarshalErr *tablemap.UnmarshalError
+			assert.ErrorAs(t, e, &unmarshalErr)
+			rows = append(rows, unmarshalErr.Row)
+		}
+		assert.Equal(t, []int{1, 3}, rows)
+
+		assert.Equal(t, []Person{
+			{Name: "Alice", Age: 23},
+			{Name: "Charlie", Age: 27},
+		}, result)
+	})
+
+	t.Run("collect errors without errors", func(t *testing.T) {
+		var result []Person
+		opts := tablemap.DefaultOptions()
+		opts.CollectErrors = true
+		err := tablemap.UnmarshalWithOptions(header, data[:1], &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []Person{{Name: "Alice", Age: 23}}, result)
+	})
+
+	t.Run("max errors", func(t *testing.T) {
+		var result []Person
+		opts := tablemap.DefaultOptions().WithCollectErrors(true).WithMaxErrors(1)
+		err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+
+		var unmarshalErr *tablemap.UnmarshalError
+		assert.ErrorAs(t, err, &unmarshalErr)
+		assert.Equal(t, 1, unmarshalErr.Row)
+		assert.Equal(t, []Person{{Name: "Alice", Age: 23}}, result)
+	})
+
+	t.Run("max errors not reached", func(t *testing.T) {
+		var result []Person
+		opts := tablemap.DefaultOptions().WithCollectErrors(true).WithMaxErrors(3)
+		err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.Error(t, err)
+		assert.Equal(t, []Person{
+			{Name: "Alice", Age: 23},
+			{Name: "Charlie", Age: 27},
+		}, result)
+	})
+}
+
+func TestUnmarshalWithOptions_defaultValues(t *testing.T) {
+	type Record struct {
+		Name    string  `table:"name"`
+		Country string  `table:"country"`
+		Score   int     `table:"score"`
+		Level   *int    `table:"level"`
+		Note    *string `table:"note"`
+	}
+
+	opts := tablemap.DefaultOptions()
+	opts.DefaultValues = map[string]string{
+		"country": "JP",
+		"score":   "100",
+		"level":   "1",
+	}
+
+	header := []string{"name", "country", "score", "level", "note"}
+	data := [][]string{
+		{"Alice", "", "", "", ""},
+		{"Bob", "\\N", "\\N", "\\N", "\\N"},
+		{"Charlie", "US", "50", "3", "hi"},
+	}
+
+	var result []Record
+	err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{
+		{Name: "Alice", Country: "JP", Score: 100, Level: P(1), Note: nil},
+		{Name: "Bob", Country: "JP", Score: 100, Level: P(1), Note: nil},
+		{Name: "Charlie", Country: "US", Score: 50, Level: P(3), Note: P("hi")},
+	}, result)
+}
+
+func TestUnmarshalWithOptions_allowRaggedRows(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+		City string `table:"city"`
+	}
+
+	header := []string{"name", "age", "city"}
+	data := [][]string{
+		{"Alice", "23", "Tokyo"},
+		{"Bob", "25"},
+		{"Charlie"},
+		{"Dave", "27", "Osaka", "junk", "more junk"},
+	}
+
+	t.Run("strict by default", func(t *testing.T) {
+		var result []Record
+		err := tablemap.UnmarshalWithOptions(header, data, &result, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("structs", func(t *testing.T) {
+		var result []Record
+		opts := tablemap.DefaultOptions().WithAllowRaggedRows(true)
+		err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{
+			{Name: "Alice", Age: 23, City: "Tokyo"},
+			{Name: "Bob", Age: 25},
+			{Name: "Charlie"},
+			{Name: "Dave", Age: 27, City: "Osaka"},
+		}, result)
+	})
+
+	t.Run("maps", func(t *testing.T) {
+		var result []map[string]string
+		opts := tablemap.DefaultOptions().WithAllowRaggedRows(true)
+		err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []map[string]string{
+			{"name": "Alice", "age": "23", "city": "Tokyo"},
+			{"name": "Bob", "age": "25"},
+			{"name": "Charlie"},
+			{"name": "Dave", "age": "27", "city": "Osaka"},
+		}, result)
+	})
+}
+
+func TestUnmarshalWithOptions_emptyStringNotNil(t *testing.T) {
+	type Record struct {
+		Str *string `table:"str"`
+		Int *int    `table:"int"`
+	}
+
+	header := []string{"str", "int"}
+
+	t.Run("empty cell is nil by default", func(t *testing.T) {
+		var result []Record
+		err := tablemap.Unmarshal(header, [][]string{{"", ""}}, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{{}}, result)
+	})
+
+	t.Run("empty string round trip", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithEmptyStringNotNil(true)
+		input := []Record{
+			{Str: P(""), Int: P(1)},
+			{Str: nil, Int: nil},
+		}
+
+		_, data, err := tablemap.MarshalWithOptions(input, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"", "1"}, {"\\N", "\\N"}}, data)
+
+		var result []Record
+		err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("empty cell is converted", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithEmptyStringNotNil(true)
+
+		var result []Record
+		err := tablemap.UnmarshalWithOptions(header, [][]string{{"a", ""}}, &result, opts)
+		assert.Error(t, err)
+	})
+}
+
+func TestMarshalWithOptions_escapeNilCollision(t *testing.T) {
+	type Record struct {
+		Str  string            `table:"str"`
+		Ptr  *string           `table:"ptr"`
+		Meta map[string]string `table:"meta,json"`
+	}
+
+	values := []string{"\\N", "\\\\N", "\\\\\\N", "\\", "N", "", "plain", "\\NULL"}
+
+	tests := []struct {
+		name string
+		opts *tablemap.Options
+	}{
+		{name: "default escape", opts: tablemap.DefaultOptions().WithEscapeNilCollision(true)},
+		{name: "custom escape", opts: tablemap.DefaultOptions().WithEscapeNilCollision(true).WithNilEscape("~")},
+		{name: "custom nil value", opts: tablemap.DefaultOptions().WithEscapeNilCollision(true).WithNilValue("NULL")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input []Record
+			for _, v := range values {
+				input = append(input, Record{Str: v, Ptr: P(v)})
+			}
+			input = append(input, Record{Str: "x", Ptr: nil})
+
+			header, data, err := tablemap.MarshalWithOptions(input, tt.opts)
+			assert.NoError(t, err)
+
+			var result []Record
+			err = tablemap.UnmarshalWithOptions(header, data, &result, tt.opts.WithEmptyStringNotNil(true))
+			assert.NoError(t, err)
+			assert.Equal(t, input, result)
+		})
+	}
+
+	t.Run("escaped cells", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithEscapeNilCollision(true)
+		_, data, err := tablemap.MarshalWithOptions([]Record{
+			{Str: "\\N", Ptr: P("\\\\N")},
+			{Str: "a\\N", Ptr: nil},
+		}, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"\\\\N", "\\\\\\N", "\\N"},
+			{"a\\N", "\\N", "\\N"},
+		}, data)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		_, data, err := tablemap.Marshal([]Record{{Str: "\\N"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "\\N", data[0][0])
+	})
+}
+
+func TestUnmarshalWithOptions_isNil(t *testing.T) {
+	type Record struct {
+		Value *int    `table:"value"`
+		Name  *string `table:"name"`
+	}
+
+	isNil := func(value string) bool {
+		switch value {
+		case "NULL", "null", "\\N", "":
+			return true
+		}
+		return false
+	}
+	opts := tablemap.DefaultOptions().WithNilValue("NULL").WithIsNil(isNil)
+
+	header := []string{"value", "name"}
+	data := [][]string{
+		{"NULL", "null"},
+		{"\\N", ""},
+		{"1", "Alice"},
+	}
+
+	var result []Record
+	err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{
+		{},
+		{},
+		{Value: P(1), Name: P("Alice")},
+	}, result)
+
+	// NilValue is still the single spelling used when marshaling
+	_, marshaled, err := tablemap.MarshalWithOptions(result, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"NULL", "NULL"},
+		{"NULL", "NULL"},
+		{"1", "Alice"},
+	}, marshaled)
+}
+
+type benchStruct struct {
+	F1  string  `table:"f1"`
+	F2  int     `table:"f2"`
+	F3  bool    `table:"f3"`
+	F4  float64 `table:"f4"`
+	F5  string  `table:"f5"`
+	F6  int64   `table:"f6"`
+	F7  uint    `table:"f7"`
+	F8  *string `table:"f8"`
+	F9  *int    `table:"f9"`
+	F10 string  `table:"f10"`
+}
+
+func BenchmarkMarshal_smallBatches(b *testing.B) {
+	str := "str"
+	num := 10
+	batch := []benchStruct{
+		{F1: "a", F2: 1, F3: true, F4: 1.5, F5: "b", F6: 2, F7: 3, F8: &str, F9: &num, F10: "c"},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 100000; j++ {
+			if _, _, err := tablemap.Marshal(batch); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkUnmarshal_smallBatches(b *testing.B) {
+	header := []string{"f1", "f2", "f3", "f4", "f5", "f6", "f7", "f8", "f9", "f10"}
+	data := [][]string{
+		{"a", "1", "true", "1.5", "b", "2", "3", "str", "10", "c"},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 100000; j++ {
+			var result []benchStruct
+			if err := tablemap.Unmarshal(header, data, &result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkRowHandler_MarshalRow(b *testing.B) {
+	str := "str"
+	num := 10
+	v := &benchStruct{F1: "a", F2: 1, F3: true, F4: 1.5, F5: "b", F6: 2, F7: 3, F8: &str, F9: &num, F10: "c"}
+
+	handler, err := tablemap.NewRowHandler[benchStruct](nil, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := handler.MarshalRow(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRowHandler_MarshalRowTo(b *testing.B) {
+	str := "str"
+	num := 10
+	v := &benchStruct{F1: "a", F2: 1, F3: true, F4: 1.5, F5: "b", F6: 2, F7: 3, F8: &str, F9: &num, F10: "c"}
+
+	handler, err := tablemap.NewRowHandler[benchStruct](nil, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var row []string
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		row, err = handler.MarshalRowTo(row, v)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Cell is an interface for fields holding a custom cell type
+type Cell interface {
+	MarshalCell() (string, error)
+}
+
+func TestMarshal_interfaceField(t *testing.T) {
+	type Record struct {
+		Cell  Cell `table:"cell"`
+		Value any  `table:"value"`
+	}
+
+	input := []Record{
+		{Cell: &CustomType{value: "a"}, Value: CustomType{value: "b"}},
+		{Cell: nil, Value: 42},
+		{Cell: nil, Value: P(1.5)},
+	}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cell", "value"}, header)
+	assert.Equal(t, [][]string{
+		{"custom:a", "custom:b"},
+		{"\\N", "42"},
+		{"\\N", "1.5"},
+	}, data)
+}
+
+func TestUnmarshal_interfaceField(t *testing.T) {
+	type Record struct {
+		Cell Cell `table:"cell"`
+	}
+
+	tests := []struct {
+		name     string
+		initial  Cell
+		value    string
+		expected Cell
+		wantErr  bool
+	}{
+		{name: "pointer", initial: &CustomType{}, value: "custom:a", expected: &CustomType{value: "a"}},
+		{name: "nil cell", initial: &CustomType{}, value: "\\N", expected: nil},
+		{name: "nil interface", initial: nil, value: "custom:a", wantErr: true},
+		{name: "non-pointer", initial: failingCell{}, value: "custom:a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := tablemap.NewRowHandler[Record]([]string{"cell"}, nil)
+			assert.NoError(t, err)
+
+			record := Record{Cell: tt.initial}
+			err = handler.UnmarshalRowInto([]string{tt.value}, &record)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, record.Cell)
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_transforms(t *testing.T) {
+	type Record struct {
+		Code  string   `table:"code"`
+		Price float64  `table:"price"`
+		Note  *string  `table:"note"`
+		Tags  []string `table:"tags,json"`
+	}
+
+	opts := tablemap.DefaultOptions().
+		WithColumnTransforms(map[string]func(string) string{
+			"code":  strings.ToUpper,
+			"price": func(s string) string { return strings.TrimPrefix(s, "$") },
+			"tags":  func(s string) string { return "[" + s + "]" },
+		}).
+		WithOutputTransforms(map[string]func(string) string{
+			"code":  strings.ToLower,
+			"price": func(s string) string { return "$" + s },
+			"tags":  func(s string) string { return strings.Trim(s, "[]") },
+		})
+
+	header := []string{"code", "price", "note", "tags"}
+	data := [][]string{
+		{"ab", "$1.5", "\\N", `"x","y"`},
+	}
+	expected := []Record{
+		{Code: "AB", Price: 1.5, Note: nil, Tags: []string{"x", "y"}},
+	}
+
+	var result []Record
+	err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	gotHeader, gotData, err := tablemap.MarshalWithOptions(result, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, header, gotHeader)
+	assert.Equal(t, data, gotData)
+}
+
+func TestMarshal_array(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	input := [2]Record{{Name: "John", Age: 30}, {Name: "Jane", Age: 25}}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "age"}, header)
+	assert.Equal(t, [][]string{{"John", "30"}, {"Jane", "25"}}, data)
+
+	header, data, err = tablemap.Marshal([0]Record{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "age"}, header)
+	assert.Equal(t, [][]string{}, data)
+}
+
+func TestUnmarshal_array(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+	header := []string{"name", "age"}
+
+	t.Run("fills from the start", func(t *testing.T) {
+		result := [3]Record{{}, {}, {Name: "kept"}}
+		err := tablemap.Unmarshal(header, [][]string{{"John", "30"}, {"Jane", "25"}}, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, [3]Record{{Name: "John", Age: 30}, {Name: "Jane", Age: 25}, {Name: "kept"}}, result)
+	})
+
+	t.Run("pointer elements", func(t *testing.T) {
+		var result [1]*Record
+		err := tablemap.Unmarshal(header, [][]string{{"John", "30"}}, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, [1]*Record{{Name: "John", Age: 30}}, result)
+	})
+
+	t.Run("skipped rows with CollectErrors", func(t *testing.T) {
+		var result [2]Record
+		opts := tablemap.DefaultOptions().WithCollectErrors(true)
+		err := tablemap.UnmarshalWithOptions(header, [][]string{{"John", "x"}, {"Jane", "25"}}, &result, opts)
+		assert.Error(t, err)
+		assert.Equal(t, [2]Record{{Name: "Jane", Age: 25}}, result)
+	})
+
+	t.Run("data exceeds the array", func(t *testing.T) {
+		var result [1]Record
+		err := tablemap.Unmarshal(header, [][]string{{"John", "30"}, {"Jane", "25"}}, &result)
+		assert.ErrorContains(t, err, "exceeding the array length 1")
+	})
+
+	t.Run("array of maps", func(t *testing.T) {
+		var result [1]map[string]string
+		err := tablemap.Unmarshal(header, [][]string{{"John", "30"}}, &result)
+		assert.Error(t, err)
+	})
+}
+
+func TestUnmarshalPositional(t *testing.T) {
+	type Record struct {
+		Name  string `table:"name"`
+		Age   int    `table:"age"`
+		Email string `table:"email"`
+	}
+
+	tests := []struct {
+		name      string
+		data      [][]string
+		opts      *tablemap.Options
+		expected  []Record
+		wantError bool
+	}{
+		{
+			name: "declaration order",
+			data: [][]string{{"John", "30", "john@example.com"}},
+			expected: []Record{
+				{Name: "John", Age: 30, Email: "john@example.com"},
+			},
+		},
+		{
+			name: "Options.Header",
+			data: [][]string{{"john@example.com", "John"}},
+			opts: tablemap.DefaultOptions().WithHeader([]string{"email", "name"}),
+			expected: []Record{
+				{Name: "John", Email: "john@example.com"},
+			},
+		},
+		{
+			name: "output aliases",
+			data: [][]string{{"John", "30", "john@example.com"}},
+			opts: tablemap.DefaultOptions().WithOutputAliases(map[string]string{"name": "Full Name"}),
+			expected: []Record{
+				{Name: "John", Age: 30, Email: "john@example.com"},
+			},
+		},
+		{
+			name:      "row length mismatch",
+			data:      [][]string{{"John", "30"}},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalPositional(tt.data, &result, tt.opts)
+			if tt.wantError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("pointer elements", func(t *testing.T) {
+		var result []*Record
+		err := tablemap.UnmarshalPositional([][]string{{"John", "30", ""}}, &result, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []*Record{{Name: "John", Age: 30}}, result)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		var result []map[string]string
+		assert.Error(t, tablemap.UnmarshalPositional(nil, &result, nil))
+		assert.Error(t, tablemap.UnmarshalPositional(nil, result, nil))
+	})
+}
+
+func TestUnmarshalWithOptions_duplicateHeader(t *testing.T) {
+	type Record struct {
+		ID   int    `table:"id"`
+		Name string `table:"name"`
+	}
+
+	tests := []struct {
+		name     string
+		header   []string
+		opts     *tablemap.Options
+		expected []Record
+		wantErr  string
+	}{
+		{
+			name:     "last column wins by default",
+			header:   []string{"id", "name", "id"},
+			expected: []Record{{ID: 2, Name: "John"}},
+		},
+		{
+			name:    "duplicate column",
+			header:  []string{"id", "name", "id"},
+			opts:    tablemap.DefaultOptions().WithErrorOnDuplicateHeader(true),
+			wantErr: "duplicate column in header: id",
+		},
+		{
+			name:   "duplicate through alias",
+			header: []string{"id", "name", "ID"},
+			opts: tablemap.DefaultOptions().
+				WithErrorOnDuplicateHeader(true).
+				WithHeaderAliases(map[string]string{"ID": "id"}),
+			wantErr: "duplicate column in header: ID",
+		},
+		{
+			name:    "duplicate unmapped column",
+			header:  []string{"note", "name", "note"},
+			opts:    tablemap.DefaultOptions().WithErrorOnDuplicateHeader(true),
+			wantErr: "duplicate column in header: note",
+		},
+		{
+			name:     "no duplicates",
+			header:   []string{"id", "name", "note"},
+			opts:     tablemap.DefaultOptions().WithErrorOnDuplicateHeader(true),
+			expected: []Record{{ID: 1, Name: "John"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions(tt.header, [][]string{{"1", "John", "2"}}, &result, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMarshalAppend(t *testing.T) {
+	type Record struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	var data [][]string
+	header, data, err := tablemap.MarshalAppend(data, []Record{{Name: "John", Age: 30}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "age"}, header)
+
+	header, data, err = tablemap.MarshalAppend(data, []Record{{Name: "Jane", Age: 25}, {Name: "Bob", Age: 40}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "age"}, header)
+	assert.Equal(t, [][]string{
+		{"John", "30"},
+		{"Jane", "25"},
+		{"Bob", "40"},
+	}, data)
+
+	t.Run("empty batch", func(t *testing.T) {
+		header, got, err := tablemap.MarshalAppend(data, []Record{}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name", "age"}, header)
+		assert.Equal(t, data, got)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, got, err := tablemap.MarshalAppend(data, Record{}, nil)
+		assert.Error(t, err)
+		assert.Equal(t, data, got)
+	})
+
+	t.Run("error in a row keeps dst", func(t *testing.T) {
+		type Invalid struct {
+			Name string      `table:"name"`
+			Cell failingCell `table:"cell"`
+		}
+		dst := [][]string{{"x", "y"}}
+		_, got, err := tablemap.MarshalAppend(dst, []Invalid{{Name: "a"}}, nil)
+		assert.ErrorIs(t, err, errMarshal)
+		assert.Equal(t, [][]string{{"x", "y"}}, got)
+	})
+}
+
+func TestUnmarshalWithOptions_intBase(t *testing.T) {
+	type Record struct {
+		Int  int    `table:"int"`
+		Uint uint16 `table:"uint"`
+	}
+
+	tests := []struct {
+		name     string
+		opts     *tablemap.Options
+		row      []string
+		expected Record
+		wantErr  bool
+	}{
+		{name: "default", opts: nil, row: []string{"010", "255"}, expected: Record{Int: 10, Uint: 255}},
+		{name: "zero value options", opts: &tablemap.Options{}, row: []string{"010", "255"}, expected: Record{Int: 10, Uint: 255}},
+		{name: "prefix rejected by default", opts: nil, row: []string{"0xFF", "0"}, wantErr: true},
+		{name: "hex", opts: tablemap.DefaultOptions().WithIntBase(16), row: []string{"-ff", "FFFF"}, expected: Record{Int: -255, Uint: 65535}},
+		{name: "auto hex", opts: tablemap.DefaultOptions().WithIntBase(tablemap.IntBaseAuto), row: []string{"0xFF", "0o755"}, expected: Record{Int: 255, Uint: 0o755}},
+		{name: "auto binary and decimal", opts: tablemap.DefaultOptions().WithIntBase(tablemap.IntBaseAuto), row: []string{"0b101", "42"}, expected: Record{Int: 5, Uint: 42}},
+		{name: "auto overflow", opts: tablemap.DefaultOptions().WithIntBase(tablemap.IntBaseAuto), row: []string{"0", "0x10000"}, wantErr: true},
+		{name: "invalid base", opts: tablemap.DefaultOptions().WithIntBase(1), row: []string{"1", "1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions([]string{"int", "uint"}, [][]string{tt.row}, &result, tt.opts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result[0])
+		})
+	}
+}
+
+func TestMarshalWithOptions_intBase(t *testing.T) {
+	type Record struct {
+		Int  int    `table:"int"`
+		Uint uint16 `table:"uint"`
+	}
+
+	input := []Record{{Int: -255, Uint: 0o755}}
+
+	tests := []struct {
+		name     string
+		opts     *tablemap.Options
+		expected []string
+	}{
+		{name: "hex", opts: tablemap.DefaultOptions().WithIntBase(16), expected: []string{"-ff", "1ed"}},
+		{name: "auto is decimal", opts: tablemap.DefaultOptions().WithIntBase(tablemap.IntBaseAuto), expected: []string{"-255", "493"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, data, err := tablemap.MarshalWithOptions(input, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, [][]string{tt.expected}, data)
+
+			// Round trip
+			var result []Record
+			err = tablemap.UnmarshalWithOptions([]string{"int", "uint"}, data, &result, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, input, result)
+		})
+	}
+
+	t.Run("invalid base", func(t *testing.T) {
+		for _, base := range []int{1, 37, 40, -2} {
+			_, _, err := tablemap.MarshalWithOptions(input, tablemap.DefaultOptions().WithIntBase(base))
+			assert.ErrorContains(t, err, "invalid IntBase: "+strconv.Itoa(base))
+		}
+	})
+}
+
+func TestThousandsSep(t *testing.T) {
+	type Record struct {
+		Int   int     `table:"int"`
+		Uint  uint64  `table:"uint"`
+		Float float64 `table:"float"`
+		Ptr   *int    `table:"ptr"`
+		Name  string  `table:"name"`
+	}
+	header := []string{"int", "uint", "float", "ptr", "name"}
+
+	tests := []struct {
+		name     string
+		opts     *tablemap.Options
+		input    []Record
+		expected [][]string
+	}{
+		{
+			name: "comma",
+			opts: tablemap.DefaultOptions().WithThousandsSep(","),
+			input: []Record{
+				{Int: -1234567, Uint: 1000, Float: 1234567.891, Ptr: P(123), Name: "1,234"},
+				{Int: 999, Uint: 0, Float: -0.5},
+			},
+			expected: [][]string{
+				{"-1,234,567", "1,000", "1,234,567.891", "123", "1,234"},
+				{"999", "0", "-0.5", "\\N", ""},
+			},
+		},
+		{
+			name:     "space",
+			opts:     tablemap.DefaultOptions().WithThousandsSep(" "),
+			input:    []Record{{Int: 12345, Float: 1e6, Ptr: P(1234)}},
+			expected: [][]string{{"12 345", "0", "1 000 000", "1 234", ""}},
+		},
+		{
+			name:     "exponent format",
+			opts:     tablemap.DefaultOptions().WithThousandsSep(",").WithFloatFormat('e', 2),
+			input:    []Record{{Float: 1234567}},
+			expected: [][]string{{"0", "0", "1.23e+06", "\\N", ""}},
+		},
+		{
+			name:     "not used with other bases",
+			opts:     tablemap.DefaultOptions().WithThousandsSep(",").WithIntBase(16),
+			input:    []Record{{Int: 0xfffff, Uint: 0xfffff}},
+			expected: [][]string{{"fffff", "fffff", "0", "\\N", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, data, err := tablemap.MarshalWithOptions(tt.input, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+
+	t.Run("unmarshal", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithThousandsSep(",")
+		data := [][]string{
+			{"-1,234,567", "1,000", "1,234.5", "12,3", "1,234"},
+			{"1234", "10", "0.25", "\\N", "a,b"},
+		}
+
+		var result []Record
+		err := tablemap.UnmarshalWithOptions(header, data, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []Record{
+			{Int: -1234567, Uint: 1000, Float: 1234.5, Ptr: P(123), Name: "1,234"},
+			{Int: 1234, Uint: 10, Float: 0.25, Name: "a,b"},
+		}, result)
+
+		// Without ThousandsSep, grouped numbers are rejected
+		err = tablemap.Unmarshal(header, data, &result)
+		assert.Error(t, err)
+	})
+}
+
+func TestMarshalWithOptions_omitZeroRows(t *testing.T) {
+	type Inner struct {
+		Note string `table:"note"`
+	}
+	type Record struct {
+		Name   string  `table:"name"`
+		Score  *int    `table:"score"`
+		Inner  *Inner  `table:"inner"`
+		Hidden float64 // Not mapped
+	}
+
+	input := []Record{
+		{Name: "Alice"},
+		{},
+		{Score: P(0)},
+		{Inner: &Inner{}},
+		{Inner: &Inner{Note: "x"}},
+		{Hidden: 1.5},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		_, data, err := tablemap.Marshal(input)
+		assert.NoError(t, err)
+		assert.Len(t, data, 6)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithOmitZeroRows(true)
+		header, data, err := tablemap.MarshalWithOptions(input, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name", "score", "inner.note"}, header)
+		assert.Equal(t, [][]string{
+			{"Alice", "\\N", "\\N"},
+			{"", "0", "\\N"},
+			{"", "\\N", "x"},
+		}, data)
+	})
+
+	t.Run("only marshaled columns count", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithOmitZeroRows(true)
+		_, data, err := tablemap.MarshalOrdered(input, []string{"score"}, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"0"}}, data)
+	})
+
+	t.Run("all rows omitted", func(t *testing.T) {
+		opts := tablemap.DefaultOptions().WithOmitZeroRows(true)
+		header, data, err := tablemap.MarshalWithOptions([]Record{{}, {}}, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name", "score", "inner.note"}, header)
+		assert.Empty(t, data)
+	})
+}
+
+func TestMarshal_complex(t *testing.T) {
+	type Record struct {
+		C128 complex128  `table:"c128"`
+		C64  complex64   `table:"c64"`
+		Ptr  *complex128 `table:"ptr"`
+	}
+
+	input := []Record{
+		{C128: 3 + 4i, C64: 1.5 - 2i, Ptr: P(complex(0, 1))},
+		{C128: 0, C64: -1, Ptr: nil},
+	}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c128", "c64", "ptr"}, header)
+	assert.Equal(t, [][]string{
+		{"(3+4i)", "(1.5-2i)", "(0+1i)"},
+		{"(0+0i)", "(-1+0i)", "\\N"},
+	}, data)
+
+	// Round trip
+	var result []Record
+	err = tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+}
+
+func TestUnmarshal_complex(t *testing.T) {
+	type Record struct {
+		C64 complex64 `table:"c64"`
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected complex64
+		wantErr  bool
+	}{
+		{name: "parenthesized", value: "(3+4i)", expected: 3 + 4i},
+		{name: "bare", value: "3+4i", expected: 3 + 4i},
+		{name: "real only", value: "2.5", expected: 2.5},
+		{name: "imaginary only", value: "-2i", expected: -2i},
+		{name: "overflow", value: "1e39+0i", wantErr: true},
+		{name: "invalid", value: "3+4j", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.Unmarshal([]string{"c64"}, [][]string{{tt.value}}, &result)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result[0].C64)
+		})
+	}
+}
+
+func TestMarshalWithOptions_nilValuesPerKind(t *testing.T) {
+	type Record struct {
+		Name  *string               `table:"name"`
+		Age   *int                  `table:"age"`
+		Score tablemap.Null[int]    `table:"score"`
+		Note  tablemap.Null[string] `table:"note"`
+		Tags  map[string]string     `table:"tags"`
+	}
+
+	opts := tablemap.DefaultOptions().WithNilValuesPerKind(map[reflect.Kind]string{reflect.String: ""})
+
+	input := []Record{
+		{},
+		{Name: P("John"), Age: P(30), Score: tablemap.Null[int]{Value: 5, Valid: true}, Note: tablemap.Null[string]{Value: "hi", Valid: true}, Tags: map[string]string{"a": "b"}},
+	}
+
+	header, data, err := tablemap.MarshalWithOptions(input, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "age", "score", "note", "tags"}, header)
+	assert.Equal(t, [][]string{
+		{"", "\\N", "\\N", "", "\\N"},
+		{"John", "30", "5", "hi", "a=b"},
+	}, data)
+
+	var result []Record
+	err = tablemap.UnmarshalWithOptions(header, data, &result, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+
+	t.Run("marker of another kind is not nil", func(t *testing.T) {
+		var result []Record
+		err := tablemap.UnmarshalWithOptions([]string{"name"}, [][]string{{"\\N"}}, &result, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, P("\\N"), result[0].Name)
+
+		err = tablemap.UnmarshalWithOptions([]string{"age"}, [][]string{{""}}, &result, opts.WithEmptyStringNotNil(true))
+		assert.Error(t, err)
+	})
+}
+
+func TestMarshal_netTypes(t *testing.T) {
+	type Record struct {
+		IP     net.IP     `table:"ip"`
+		URL    *url.URL   `table:"url"`
+		URLVal url.URL    `table:"url_val"`
+		Net    net.IPNet  `table:"net"`
+		NetPtr *net.IPNet `table:"net_ptr"`
+	}
+
+	u, err := url.Parse("https://user@example.com/a%20b?q=1#top")
+	assert.NoError(t, err)
+	_, n, err := net.ParseCIDR("2001:db8::/32")
+	assert.NoError(t, err)
+
+	input := []Record{
+		{IP: net.ParseIP("192.0.2.1"), URL: u, URLVal: *u, Net: *n, NetPtr: n},
+		{IP: net.ParseIP("2001:db8::1")},
+	}
+
+	header, data, err := tablemap.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"192.0.2.1", "https://user@example.com/a%20b?q=1#top", "https://user@example.com/a%20b?q=1#top", "2001:db8::/32", "2001:db8::/32"},
+		{"2001:db8::1", "\\N", "", "", "\\N"},
+	}, data)
+
+	// Round trip
+	var result []Record
+	err = tablemap.Unmarshal(header, data, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, input, result)
+}
+
+func TestUnmarshal_netTypesInvalid(t *testing.T) {
+	type Record struct {
+		IP  net.IP    `table:"ip"`
+		URL *url.URL  `table:"url"`
+		Net net.IPNet `table:"net"`
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{name: "IP", header: "ip", value: "999.0.0.1"},
+		{name: "URL", header: "url", value: "http://[::1"},
+		{name: "IPNet without mask", header: "net", value: "10.0.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.Unmarshal([]string{tt.header}, [][]string{{tt.value}}, &result)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_headerTransform(t *testing.T) {
+	type Record struct {
+		FirstName string `table:"first_name"`
+		LastName  string `table:"last_name"`
+	}
+
+	normalize := func(h string) string {
+		return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(h)), " ", "_")
+	}
+
+	tests := []struct {
+		name     string
+		header   []string
+		opts     *tablemap.Options
+		expected []Record
+		wantErr  string
+	}{
+		{
+			name:     "normalized",
+			header:   []string{"First Name", " LAST NAME "},
+			opts:     tablemap.DefaultOptions().WithHeaderTransform(normalize),
+			expected: []Record{{FirstName: "John", LastName: "Doe"}},
+		},
+		{
+			name:   "aliases use the transformed name",
+			header: []string{"Given Name", "Last Name"},
+			opts: tablemap.DefaultOptions().
+				WithHeaderTransform(normalize).
+				WithHeaderAliases(map[string]string{"given_name": "first_name"}),
+			expected: []Record{{FirstName: "John", LastName: "Doe"}},
+		},
+		{
+			name:    "collision",
+			header:  []string{"First Name", "first_name"},
+			opts:    tablemap.DefaultOptions().WithHeaderTransform(normalize),
+			wantErr: `headers "First Name" and "first_name" both map to column first_name`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions(tt.header, [][]string{{"John", "Doe"}}, &result, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("unmapped duplicates are ignored", func(t *testing.T) {
+		header := []string{"first_name", "note", "note", "", "", "last_name"}
+		for _, opts := range []*tablemap.Options{
+			tablemap.DefaultOptions().WithHeaderTransform(normalize),
+			tablemap.DefaultOptions().WithHeaderCaseFold(true),
+			tablemap.DefaultOptions().WithFuzzyHeaderMatch(true),
+		} {
+			var result []Record
+			err := tablemap.UnmarshalWithOptions(header, [][]string{{"John", "a", "b", "", "", "Doe"}}, &result, opts)
+			assert.NoError(t, err)
+			assert.Equal(t, []Record{{FirstName: "John", LastName: "Doe"}}, result)
+		}
+	})
+}
+
+func TestUnmarshalWithOptions_headerCaseFold(t *testing.T) {
+	type Record struct {
+		Größe string `table:"größe"`
+		Name  string `table:"name"`
+	}
+	type Ambiguous struct {
+		Lower string `table:"id"`
+		Upper string `table:"ID"`
+	}
+
+	opts := tablemap.DefaultOptions().WithHeaderCaseFold(true)
+
+	tests := []struct {
+		name     string
+		header   []string
+		opts     *tablemap.Options
+		target   any
+		expected any
+		wantErr  string
+	}{
+		{
+			name:     "unicode folding",
+			header:   []string{"GRÖßE", "Name"},
+			opts:     opts,
+			target:   &[]Record{},
+			expected: &[]Record{{Größe: "a", Name: "b"}},
+		},
+		{
+			name:     "without folding",
+			header:   []string{"GRÖßE", "Name"},
+			opts:     nil,
+			target:   &[]Record{},
+			expected: &[]Record{{}},
+		},
+		{
+			name:     "exact match wins",
+			header:   []string{"ID", "x"},
+			opts:     opts,
+			target:   &[]Ambiguous{},
+			expected: &[]Ambiguous{{Upper: "a"}},
+		},
+		{
+			name:    "ambiguous after folding",
+			header:  []string{"Id", "x"},
+			opts:    opts,
+			target:  &[]Ambiguous{},
+			wantErr: `header "Id" matches columns id, ID under case folding`,
+		},
+		{
+			name:    "headers collide after folding",
+			header:  []string{"NAME", "name"},
+			opts:    opts,
+			target:  &[]Record{},
+			wantErr: `headers "NAME" and "name" both map to column name`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tablemap.UnmarshalWithOptions(tt.header, [][]string{{"a", "b"}}, tt.target, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tt.target)
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_fuzzyHeaderMatch(t *testing.T) {
+	type Record struct {
+		Email     string `table:"email"`
+		FirstName string `table:"first_name"`
+	}
+	type Ambiguous struct {
+		A string `table:"e_mail"`
+		B string `table:"e-mail"`
+	}
+
+	opts := tablemap.DefaultOptions().WithFuzzyHeaderMatch(true)
+
+	tests := []struct {
+		name     string
+		header   []string
+		opts     *tablemap.Options
+		target   any
+		expected any
+		wantErr  string
+	}{
+		{
+			name:     "punctuation and case",
+			header:   []string{"E-Mail", "First Name"},
+			opts:     opts,
+			target:   &[]Record{},
+			expected: &[]Record{{Email: "a", FirstName: "b"}},
+		},
+		{
+			name:     "without fuzzy matching",
+			header:   []string{"E-Mail", "First Name"},
+			opts:     nil,
+			target:   &[]Record{},
+			expected: &[]Record{{}},
+		},
+		{
+			name:     "exact match wins",
+			header:   []string{"e-mail", "x"},
+			opts:     opts,
+			target:   &[]Ambiguous{},
+			expected: &[]Ambiguous{{B: "a"}},
+		},
+		{
+			name:     "only punctuation",
+			header:   []string{"--", "x"},
+			opts:     opts,
+			target:   &[]Record{},
+			expected: &[]Record{{}},
+		},
+		{
+			name:    "ambiguous after normalizing",
+			header:  []string{"E.Mail", "x"},
+			opts:    opts,
+			target:  &[]Ambiguous{},
+			wantErr: `header "E.Mail" matches columns e_mail, e-mail under fuzzy matching`,
+		},
+		{
+			name:    "headers collide after normalizing",
+			header:  []string{"e-mail", "email"},
+			opts:    opts,
+			target:  &[]Record{},
+			wantErr: `headers "e-mail" and "email" both map to column email`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tablemap.UnmarshalWithOptions(tt.header, [][]string{{"a", "b"}}, tt.target, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tt.target)
+		})
+	}
+}
+
+func TestMarshalWithOptions_tagName(t *testing.T) {
+	type Address struct {
+		City string `csv:"city" table:"town"`
+	}
+	type Base struct {
+		ID int `csv:"id" table:"key"`
+	}
+	type Record struct {
+		Base
+		Name    string  `csv:"name" table:"full_name"`
+		Age     int     `csv:"age,required"`
+		Note    string  `table:"note"`
+		Address Address `csv:"address" table:"addr"`
+		Skip    string  `csv:"-" table:"skip"`
+	}
+	input := []Record{{Base: Base{ID: 1}, Name: "Alice", Age: 23, Note: "n", Address: Address{City: "Tokyo"}, Skip: "s"}}
+
+	tests := []struct {
+		name           string
+		opts           *tablemap.Options
+		expectedHeader []string
+		expectedData   [][]string
+		expected       []Record
+	}{
+		{
+			name:           "default tag",
+			opts:           nil,
+			expectedHeader: []string{"key", "full_name", "note", "addr.town", "skip"},
+			expectedData:   [][]string{{"1", "Alice", "n", "Tokyo", "s"}},
+			expected:       []Record{{Base: Base{ID: 1}, Name: "Alice", Note: "n", Address: Address{City: "Tokyo"}, Skip: "s"}},
+		},
+		{
+			name:           "csv tag",
+			opts:           tablemap.DefaultOptions().WithTagName("csv"),
+			expectedHeader: []string{"id", "name", "age", "address.city"},
+			expectedData:   [][]string{{"1", "Alice", "23", "Tokyo"}},
+			expected:       []Record{{Base: Base{ID: 1}, Name: "Alice", Age: 23, Address: Address{City: "Tokyo"}}},
+		},
+		{
+			name:           "empty tag name",
+			opts:           tablemap.DefaultOptions().WithTagName(""),
+			expectedHeader: []string{"key", "full_name", "note", "addr.town", "skip"},
+			expectedData:   [][]string{{"1", "Alice", "n", "Tokyo", "s"}},
+			expected:       []Record{{Base: Base{ID: 1}, Name: "Alice", Note: "n", Address: Address{City: "Tokyo"}, Skip: "s"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, data, err := tablemap.MarshalWithOptions(input, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedHeader, header)
+			assert.Equal(t, tt.expectedData, data)
+
+			var result []Record
+			err = tablemap.UnmarshalWithOptions(header, data, &result, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
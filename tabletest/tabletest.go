@@ -0,0 +1,104 @@
+package tabletest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kmio11/tablemap"
+)
+
+// AssertRoundTrip marshals values with opts, unmarshals the result back and reports
+// an error on t for each field that does not come back equal to the original.
+// T must be a struct type. This is useful to test CellMarshaler and CellUnmarshaler
+// implementations, and options such as NilValue, against representative values.
+// Fields are compared with reflect.DeepEqual, except for values of types with an
+// Equal method, such as time.Time, which is used instead.
+// It returns whether the round trip succeeded.
+func AssertRoundTrip[T any](t testing.TB, values []T, opts *tablemap.Options) bool {
+	t.Helper()
+
+	header, data, err := tablemap.MarshalWithOptions(values, opts)
+	if err != nil {
+		t.Errorf("marshal: %v", err)
+		return false
+	}
+
+	var result []T
+	if err := tablemap.UnmarshalWithOptions(header, data, &result, opts); err != nil {
+		t.Errorf("unmarshal: %v", err)
+		return false
+	}
+	if len(result) != len(values) {
+		t.Errorf("got %d values back, want %d", len(result), len(values))
+		return false
+	}
+
+	ok := true
+	for i := range values {
+		for _, d := range diff(reflect.ValueOf(values[i]), reflect.ValueOf(result[i]), "") {
+			t.Errorf("values[%d]%s: %s (cells %q)", i, d.path, d.cause, data[i])
+			ok = false
+		}
+	}
+	return ok
+}
+
+// difference is a value that did not round-trip, identified by its path from the struct
+type difference struct {
+	path  string
+	cause string
+}
+
+// diff returns the paths of the values that differ between want and got,
+// descending into structs and non-nil pointers
+func diff(want, got reflect.Value, path string) []difference {
+	if want.Kind() == reflect.Ptr {
+		switch {
+		case want.IsNil() && got.IsNil():
+			return nil
+		case want.IsNil():
+			return []difference{{path, fmt.Sprintf("got non-nil %#v, want nil", got.Elem())}}
+		case got.IsNil():
+			return []difference{{path, fmt.Sprintf("got nil, want %#v", want.Elem())}}
+		}
+		return diff(want.Elem(), got.Elem(), path)
+	}
+
+	if eq, ok := equalMethod(want, got); ok {
+		if eq {
+			return nil
+		}
+		return []difference{{path, fmt.Sprintf("got %v, want %v", got, want)}}
+	}
+
+	if want.Kind() == reflect.Struct {
+		var diffs []difference
+		for i := 0; i < want.NumField(); i++ {
+			if !want.Type().Field(i).IsExported() {
+				continue
+			}
+			diffs = append(diffs, diff(want.Field(i), got.Field(i), path+"."+want.Type().Field(i).Name)...)
+		}
+		return diffs
+	}
+
+	if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+		return []difference{{path, fmt.Sprintf("got %#v, want %#v", got, want)}}
+	}
+	return nil
+}
+
+// equalMethod compares want and got with an Equal method of their type, if any,
+// such as the one of time.Time which ignores the monotonic clock reading
+func equalMethod(want, got reflect.Value) (eq bool, ok bool) {
+	m := want.MethodByName("Equal")
+	if !m.IsValid() {
+		return false, false
+	}
+	mt := m.Type()
+	if mt.NumIn() != 1 || mt.In(0) != want.Type() || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool {
+		return false, false
+	}
+	return m.Call([]reflect.Value{got})[0].Bool(), true
+}
@@ -0,0 +1,104 @@
+package tabletest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kmio11/tablemap"
+	"github.com/kmio11/tablemap/tabletest"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the errors reported by AssertRoundTrip instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// Upper is a CellMarshaler that loses the case of its value
+type Upper string
+
+func (u Upper) MarshalCell() (string, error) {
+	return strings.ToUpper(string(u)), nil
+}
+
+func (u *Upper) UnmarshalCell(s string) error {
+	*u = Upper(s)
+	return nil
+}
+
+func TestAssertRoundTrip(t *testing.T) {
+	type Inner struct {
+		Note string `table:"note"`
+	}
+	type Record struct {
+		Name  string    `table:"name"`
+		Age   *int      `table:"age"`
+		At    time.Time `table:"at"`
+		Inner Inner     `table:"inner"`
+	}
+
+	age := 23
+	jst := time.FixedZone("JST", 9*60*60)
+
+	t.Run("symmetric", func(t *testing.T) {
+		r := &recorder{TB: t}
+		ok := tabletest.AssertRoundTrip(r, []Record{
+			{Name: "Alice", Age: &age, At: time.Date(2024, 1, 1, 9, 0, 0, 0, jst), Inner: Inner{Note: "x"}},
+			{Name: "Bob"},
+		}, nil)
+		assert.True(t, ok)
+		assert.Empty(t, r.errors)
+	})
+
+	t.Run("nil value collides with a cell", func(t *testing.T) {
+		r := &recorder{TB: t}
+		ok := tabletest.AssertRoundTrip(r, []struct {
+			Name *string `table:"name"`
+		}{
+			{Name: new(string)},
+		}, tablemap.DefaultOptions().WithNilValue(""))
+		assert.False(t, ok)
+		assert.Equal(t, []string{`values[0].Name: got nil, want "" (cells [""])`}, r.errors)
+	})
+
+	t.Run("unmarshal error", func(t *testing.T) {
+		r := &recorder{TB: t}
+		ok := tabletest.AssertRoundTrip(r, []Record{
+			{Name: "Alice"},
+		}, tablemap.DefaultOptions().WithNilValue("Alice"))
+		assert.False(t, ok)
+		assert.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "unmarshal: ")
+	})
+
+	t.Run("asymmetric marshaler", func(t *testing.T) {
+		type Tagged struct {
+			ID    int   `table:"id"`
+			Label Upper `table:"label"`
+		}
+
+		r := &recorder{TB: t}
+		ok := tabletest.AssertRoundTrip(r, []Tagged{
+			{ID: 1, Label: "ABC"},
+			{ID: 2, Label: "abc"},
+		}, nil)
+		assert.False(t, ok)
+		assert.Equal(t, []string{`values[1].Label: got "ABC", want "abc" (cells ["2" "ABC"])`}, r.errors)
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		r := &recorder{TB: t}
+		ok := tabletest.AssertRoundTrip(r, []int{1}, nil)
+		assert.False(t, ok)
+		assert.Equal(t, []string{"marshal: slice elements must be structs"}, r.errors)
+	})
+}
@@ -0,0 +1,78 @@
+package tablemap
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagOptions is the string following a comma in a struct field's table tag,
+// or the empty string.
+type tagOptions string
+
+// lookupTag returns the name and options of a struct field's tag under the key tagName.
+// It reports false if the field has no tag, an empty name, or is ignored with "-".
+// Following the encoding/json convention, "-," maps the field to a column literally named "-".
+func lookupTag(field reflect.StructField, tagName string) (string, tagOptions, bool) {
+	tag := field.Tag.Get(tagName)
+	if tag == ignore {
+		return "", "", false
+	}
+	name, opts := parseTag(tag)
+	if name == "" {
+		return "", "", false
+	}
+	return name, opts, true
+}
+
+// parseTag splits a struct field's table tag into its name and comma-separated options
+func parseTag(tag string) (string, tagOptions) {
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, tagOptions(opts)
+}
+
+// Contains reports whether a comma-separated list of options contains a particular option
+func (o tagOptions) Contains(option string) bool {
+	s := string(o)
+	for s != "" {
+		if strings.HasPrefix(s, tagOptDefault) {
+			// The rest of the tag is the default value
+			return false
+		}
+		var name string
+		name, s, _ = strings.Cut(s, ",")
+		if name == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the value of an option of the form "name=value", given the prefix "name=".
+// Options after "default=" are part of the default value and are not searched.
+func (o tagOptions) Value(prefix string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		if strings.HasPrefix(s, tagOptDefault) {
+			return "", false
+		}
+		var option string
+		option, s, _ = strings.Cut(s, ",")
+		if value, ok := strings.CutPrefix(option, prefix); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Default returns the value of the "default=" option, which must be the last option.
+// The value extends to the end of the tag, so it may contain commas and equal signs.
+func (o tagOptions) Default() (string, bool) {
+	s := string(o)
+	for s != "" {
+		if value, ok := strings.CutPrefix(s, tagOptDefault); ok {
+			return value, true
+		}
+		_, s, _ = strings.Cut(s, ",")
+	}
+	return "", false
+}
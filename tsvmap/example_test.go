@@ -0,0 +1,61 @@
+package tsvmap_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kmio11/tablemap/tsvmap"
+)
+
+func ExampleReader_ReadAll() {
+	tsvData := "name\tage\temail\n" +
+		"John Doe\t30\tjohn@example.com\n" +
+		"Jane Smith\t25\tjane@example.com\n"
+
+	type Person struct {
+		Name  string `table:"name"`
+		Age   int    `table:"age"`
+		Email string `table:"email"`
+	}
+
+	reader := tsvmap.NewReader[Person](strings.NewReader(tsvData), nil)
+	persons, err := reader.ReadAll()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	for _, p := range persons {
+		fmt.Printf("%s is %d years old (email: %s)\n", p.Name, p.Age, p.Email)
+	}
+	// Output:
+	// John Doe is 30 years old (email: john@example.com)
+	// Jane Smith is 25 years old (email: jane@example.com)
+}
+
+func ExampleWriter_WriteAll() {
+	type Person struct {
+		Name  string `table:"name"`
+		Age   int    `table:"age"`
+		Email string `table:"email"`
+	}
+
+	persons := []Person{
+		{Name: "John Doe", Age: 30, Email: "john@example.com"},
+		{Name: "Jane Smith", Age: 25, Email: "jane@example.com"},
+	}
+
+	var buf bytes.Buffer
+	writer := tsvmap.NewWriter[Person](&buf, nil)
+	if err := writer.WriteAll(persons); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	fmt.Print(strings.ReplaceAll(buf.String(), "\t", "<TAB>"))
+	// Output:
+	// name<TAB>age<TAB>email
+	// John Doe<TAB>30<TAB>john@example.com
+	// Jane Smith<TAB>25<TAB>jane@example.com
+}
@@ -0,0 +1,37 @@
+package tsvmap
+
+import (
+	"io"
+
+	"github.com/kmio11/tablemap"
+	"github.com/kmio11/tablemap/csvmap"
+)
+
+// Reader is a TSV reader that can unmarshal data into structs.
+type Reader[T any] struct {
+	*csvmap.Reader[T]
+}
+
+// NewReader creates a new Reader with optional tablemap.Options.
+// The underlying csv.Reader is configured with Comma = '\t' and LazyQuotes = true.
+func NewReader[T any](r io.Reader, opts *tablemap.Options) *Reader[T] {
+	cr := csvmap.NewReaderConfig[T](r, opts, &csvmap.Config{
+		Delimiter:  '\t',
+		LazyQuotes: true,
+	})
+	return &Reader[T]{Reader: cr}
+}
+
+// Writer is a TSV writer that can marshal structs into TSV format.
+type Writer[T any] struct {
+	*csvmap.Writer[T]
+}
+
+// NewWriter creates a new Writer with optional tablemap.Options.
+// The underlying csv.Writer is configured with Comma = '\t'.
+func NewWriter[T any](w io.Writer, opts *tablemap.Options) *Writer[T] {
+	cw := csvmap.NewWriterConfig[T](w, opts, &csvmap.Config{
+		Delimiter: '\t',
+	})
+	return &Writer[T]{Writer: cw}
+}
@@ -0,0 +1,116 @@
+package tsvmap_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/kmio11/tablemap/tsvmap"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestStruct struct {
+	String string `table:"string"`
+	Int    int    `table:"int"`
+	Ptr    *int   `table:"ptr"`
+}
+
+func P[T any](t T) *T {
+	return &t
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     *tablemap.Options
+		expected []TestStruct
+	}{
+		{
+			name:  "basic",
+			input: "string\tint\tptr\ntest1\t123\t1\ntest2\t456\t2\n",
+			expected: []TestStruct{
+				{String: "test1", Int: 123, Ptr: P(1)},
+				{String: "test2", Int: 456, Ptr: P(2)},
+			},
+		},
+		{
+			name:  "comma is not a delimiter",
+			input: "string\tint\tptr\na,b\t123\t1\n",
+			expected: []TestStruct{
+				{String: "a,b", Int: 123, Ptr: P(1)},
+			},
+		},
+		{
+			name:  "lazy quotes",
+			input: "string\tint\tptr\n5\" disk\t123\t1\n",
+			expected: []TestStruct{
+				{String: `5" disk`, Int: 123, Ptr: P(1)},
+			},
+		},
+		{
+			name:  "nil value",
+			input: "string\tint\tptr\ntest1\t123\tNULL\n",
+			opts:  &tablemap.Options{NilValue: "NULL"},
+			expected: []TestStruct{
+				{String: "test1", Int: 123, Ptr: nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := tsvmap.NewReader[TestStruct](strings.NewReader(tt.input), tt.opts)
+			result, err := reader.ReadAll()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestReader_Read(t *testing.T) {
+	input := "string\tint\tptr\ntest1\t123\t1\ntest2\t456\t2\n"
+	reader := tsvmap.NewReader[TestStruct](strings.NewReader(input), nil)
+
+	var result []TestStruct
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		result = append(result, *record)
+	}
+
+	assert.Equal(t, []TestStruct{
+		{String: "test1", Int: 123, Ptr: P(1)},
+		{String: "test2", Int: 456, Ptr: P(2)},
+	}, result)
+}
+
+func TestWriter(t *testing.T) {
+	input := []TestStruct{
+		{String: "test1", Int: 123, Ptr: P(1)},
+		{String: "a,b", Int: 456, Ptr: nil},
+	}
+	expected := "string\tint\tptr\ntest1\t123\t1\na,b\t456\t\\N\n"
+
+	t.Run("WriteAll", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := tsvmap.NewWriter[TestStruct](&buf, nil)
+		assert.NoError(t, writer.WriteAll(input))
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := tsvmap.NewWriter[TestStruct](&buf, nil)
+		for _, record := range input {
+			assert.NoError(t, writer.Write(record))
+		}
+		assert.NoError(t, writer.Flush())
+		assert.Equal(t, expected, buf.String())
+	})
+}
@@ -0,0 +1,32 @@
+package tablemap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FormatValue converts a value to a cell as a field of its type is converted when marshaling.
+// Nil pointers, maps and interfaces are formatted as NilValue.
+// This allows building other table formats on the same conversions.
+func FormatValue(v reflect.Value, opts *Options) (string, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if !v.IsValid() {
+		return "", fmt.Errorf("v must be a valid value")
+	}
+	return formatField(v, opts)
+}
+
+// SetValue sets dst from a cell as a field of its type is set when unmarshaling.
+// dst must be settable, such as a field of a struct reached through a pointer.
+// Cells matching NilValue set pointers, maps and interfaces to nil, and are an error for other types.
+func SetValue(dst reflect.Value, s string, opts *Options) error {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if !dst.CanSet() {
+		return fmt.Errorf("dst must be settable")
+	}
+	return setField(dst, s, opts)
+}
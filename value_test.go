@@ -0,0 +1,85 @@
+package tablemap_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kmio11/tablemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        reflect.Value
+		opts     *tablemap.Options
+		expected string
+		wantErr  string
+	}{
+		{name: "int", v: reflect.ValueOf(123), expected: "123"},
+		{name: "pointer", v: reflect.ValueOf(P("a")), expected: "a"},
+		{name: "nil pointer", v: reflect.ValueOf((*int)(nil)), expected: "\\N"},
+		{name: "nil collision", v: reflect.ValueOf("\\N"), opts: tablemap.DefaultOptions().WithEscapeNilCollision(true), expected: "\\\\N"},
+		{name: "time", v: reflect.ValueOf(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)), opts: tablemap.DefaultOptions().WithTimeLayout(time.DateOnly), expected: "2024-01-15"},
+		{name: "null", v: reflect.ValueOf(tablemap.Null[int]{}), expected: "\\N"},
+		{name: "invalid", v: reflect.Value{}, wantErr: "v must be a valid value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell, err := tablemap.FormatValue(tt.v, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, cell)
+		})
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	t.Run("int", func(t *testing.T) {
+		var v int
+		err := tablemap.SetValue(reflect.ValueOf(&v).Elem(), "123", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 123, v)
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		var v *float64
+		err := tablemap.SetValue(reflect.ValueOf(&v).Elem(), "1.5", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, P(1.5), v)
+
+		err = tablemap.SetValue(reflect.ValueOf(&v).Elem(), "\\N", nil)
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		v := tablemap.Null[int]{Value: 1, Valid: true}
+		err := tablemap.SetValue(reflect.ValueOf(&v).Elem(), "\\N", nil)
+		assert.NoError(t, err)
+		assert.False(t, v.Valid)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		var v bool
+		err := tablemap.SetValue(reflect.ValueOf(&v).Elem(), "yes", tablemap.DefaultOptions().WithBoolFormat("yes", "no"))
+		assert.NoError(t, err)
+		assert.True(t, v)
+	})
+
+	t.Run("nil to non-pointer", func(t *testing.T) {
+		var v int
+		err := tablemap.SetValue(reflect.ValueOf(&v).Elem(), "\\N", nil)
+		assert.EqualError(t, err, "cannot set nil to non-pointer field of type: int")
+	})
+
+	t.Run("not settable", func(t *testing.T) {
+		err := tablemap.SetValue(reflect.ValueOf(0), "1", nil)
+		assert.EqualError(t, err, "dst must be settable")
+	})
+}
@@ -0,0 +1,38 @@
+package xlsxmap_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kmio11/tablemap/xlsxmap"
+)
+
+func ExampleWriteAll() {
+	type Person struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"` // Written as a number
+	}
+
+	persons := []Person{
+		{Name: "John Doe", Age: 30},
+		{Name: "Jane Smith", Age: 25},
+	}
+
+	var buf bytes.Buffer
+	if err := xlsxmap.WriteAll(&buf, "People", persons, nil); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	result, err := xlsxmap.ReadAll[Person](&buf, nil)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, p := range result {
+		fmt.Printf("%+v\n", p)
+	}
+	// Output:
+	// {Name:John Doe Age:30}
+	// {Name:Jane Smith Age:25}
+}
@@ -0,0 +1,151 @@
+package xlsxmap
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/kmio11/tablemap"
+	"github.com/xuri/excelize/v2"
+)
+
+// defaultSheet is the sheet of a new workbook
+const defaultSheet = "Sheet1"
+
+// WriteAll writes a slice of struct T as a workbook with a single sheet of the given name,
+// with the header in the first row. An empty name keeps the default name "Sheet1".
+// Cells of integer and float fields are written as numbers, and other cells as text.
+// Numeric cells that do not parse as numbers, such as nil values, are written as text too.
+func WriteAll[T any](w io.Writer, sheet string, data []T, opts *tablemap.Options) error {
+	header, rows, err := tablemap.MarshalWithOptions(data, opts)
+	if err != nil {
+		return err
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if sheet != "" && sheet != defaultSheet {
+		if err := f.SetSheetName(defaultSheet, sheet); err != nil {
+			return err
+		}
+	} else {
+		sheet = defaultSheet
+	}
+
+	kinds, err := columnKinds[T](header, opts)
+	if err != nil {
+		return err
+	}
+
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	if err := sw.SetRow("A1", toValues(header, nil)); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, toValues(row, kinds)); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// columnKinds returns the kind of the field of each header column, looking through pointers
+// so that nullable numeric fields are numeric too
+func columnKinds[T any](header []string, opts *tablemap.Options) ([]reflect.Kind, error) {
+	schema, err := tablemap.SchemaOfWithOptions[T](opts)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]reflect.Kind, len(schema.Columns))
+	for _, c := range schema.Columns {
+		t := c.Type
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		byName[c.Name] = t.Kind()
+	}
+
+	kinds := make([]reflect.Kind, len(header))
+	for i, h := range header {
+		kinds[i] = byName[h]
+	}
+	return kinds, nil
+}
+
+// toValues converts a row to cell values, parsing the cells of numeric columns as numbers
+func toValues(row []string, kinds []reflect.Kind) []any {
+	values := make([]any, len(row))
+	for i, cell := range row {
+		values[i] = cell
+		if kinds == nil {
+			continue
+		}
+		switch kinds[i] {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n, err := strconv.ParseInt(cell, 10, 64); err == nil {
+				values[i] = n
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if n, err := strconv.ParseUint(cell, 10, 64); err == nil {
+				values[i] = n
+			}
+		case reflect.Float32, reflect.Float64:
+			if n, err := strconv.ParseFloat(cell, 64); err == nil {
+				values[i] = n
+			}
+		}
+	}
+	return values
+}
+
+// ReadAll reads the first sheet of a workbook and converts it to a slice of struct T.
+// The first row is the header. Cells are read as their raw values, so numbers are not
+// affected by the number format of the cell. Empty cells at the end of a row are read as empty.
+// An empty sheet returns no rows.
+func ReadAll[T any](r io.Reader, opts *tablemap.Options) ([]T, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("workbook has no sheets")
+	}
+	rows, err := f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []T
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	// Rows omit trailing empty cells, so pad them to the header
+	header, data := rows[0], rows[1:]
+	for i, row := range data {
+		if len(row) < len(header) {
+			data[i] = append(row, make([]string, len(header)-len(row))...)
+		}
+	}
+
+	if err := tablemap.UnmarshalWithOptions(header, data, &result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package xlsxmap_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kmio11/tablemap"
+	"github.com/kmio11/tablemap/xlsxmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+type TestStruct struct {
+	Name  string  `table:"name"`
+	Age   int     `table:"age"`
+	Score float64 `table:"score"`
+	Note  *string `table:"note"`
+	Code  string  `table:"code"`
+	Rank  *int    `table:"rank"`
+}
+
+func P[T any](t T) *T {
+	return &t
+}
+
+func TestWriteAll(t *testing.T) {
+	input := []TestStruct{
+		{Name: "Alice", Age: 23, Score: 1.5, Note: P("a"), Code: "007", Rank: P(2)},
+		{Name: "Bob", Age: -4, Score: 1e21},
+	}
+
+	tests := []struct {
+		name          string
+		sheet         string
+		expectedSheet string
+	}{
+		{name: "named sheet", sheet: "People", expectedSheet: "People"},
+		{name: "default sheet", sheet: "", expectedSheet: "Sheet1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := xlsxmap.WriteAll(&buf, tt.sheet, input, nil)
+			assert.NoError(t, err)
+
+			f, err := excelize.OpenReader(&buf)
+			assert.NoError(t, err)
+			defer f.Close()
+			assert.Equal(t, []string{tt.expectedSheet}, f.GetSheetList())
+
+			rows, err := f.GetRows(tt.expectedSheet, excelize.Options{RawCellValue: true})
+			assert.NoError(t, err)
+			assert.Equal(t, [][]string{
+				{"name", "age", "score", "note", "code", "rank"},
+				{"Alice", "23", "1.5", "a", "007", "2"},
+				{"Bob", "-4", "1000000000000000000000", "\\N", "", "\\N"},
+			}, rows)
+
+			// Numeric fields are numbers, and other fields are text even if they look like numbers
+			for cell, expected := range map[string]excelize.CellType{
+				"A2": excelize.CellTypeInlineString,
+				"B2": excelize.CellTypeUnset,
+				"C2": excelize.CellTypeUnset,
+				"E2": excelize.CellTypeInlineString,
+				"F2": excelize.CellTypeUnset,
+				"F3": excelize.CellTypeInlineString,
+			} {
+				typ, err := f.GetCellType(tt.expectedSheet, cell)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, typ, cell)
+			}
+		})
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		input := []TestStruct{
+			{Name: "Alice", Age: 23, Score: 1.5, Note: P("a"), Code: "007", Rank: P(2)},
+			{Name: "Bob", Age: -4, Score: 1e21},
+		}
+
+		var buf bytes.Buffer
+		err := xlsxmap.WriteAll(&buf, "People", input, nil)
+		assert.NoError(t, err)
+
+		result, err := xlsxmap.ReadAll[TestStruct](&buf, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("trailing empty cells", func(t *testing.T) {
+		f := excelize.NewFile()
+		defer f.Close()
+		assert.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]any{"name", "age", "note"}))
+		assert.NoError(t, f.SetSheetRow("Sheet1", "A2", &[]any{"Alice", 23}))
+
+		var buf bytes.Buffer
+		_, err := f.WriteTo(&buf)
+		assert.NoError(t, err)
+
+		opts := tablemap.DefaultOptions().WithNilValue("")
+		result, err := xlsxmap.ReadAll[TestStruct](&buf, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []TestStruct{{Name: "Alice", Age: 23}}, result)
+	})
+
+	t.Run("empty sheet", func(t *testing.T) {
+		f := excelize.NewFile()
+		defer f.Close()
+
+		var buf bytes.Buffer
+		_, err := f.WriteTo(&buf)
+		assert.NoError(t, err)
+
+		result, err := xlsxmap.ReadAll[TestStruct](&buf, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("not a workbook", func(t *testing.T) {
+		_, err := xlsxmap.ReadAll[TestStruct](bytes.NewReader([]byte("name,age\n")), nil)
+		assert.Error(t, err)
+	})
+}